@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"stocks/keycrypt"
+	"stocks/logging"
+	"stocks/stocksdb"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// adminToken is the bearer token of the /v1/admin endpoints. The admin API
+// answers 503 when it is empty.
+var adminToken string
+
+// keyCipher encrypts API keys added through the admin API. It is nil when
+// no master key secret is present.
+var keyCipher *keycrypt.Cipher
+
+type KeyRequest struct {
+	Name          string `json:"name"`
+	Key           string `json:"key"`
+	Limit         int64  `json:"limit"`
+	WindowSeconds int64  `json:"windowSeconds"`
+}
+
+// KeyView is a stored key with its value masked.
+type KeyView struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Key           string     `json:"key"`
+	Limit         int64      `json:"limit"`
+	WindowSeconds int64      `json:"windowSeconds"`
+	Used          int64      `json:"used"`
+	ResetAt       *time.Time `json:"resetAt,omitempty"`
+	Exhausted     bool       `json:"exhausted"`
+	Disabled      bool       `json:"disabled"`
+	Encrypted     bool       `json:"encrypted"`
+}
+
+type KeyResponse struct {
+	Success   bool      `json:"status"`
+	Message   string    `json:"message"`
+	Key       *KeyView  `json:"key,omitempty"`
+	Keys      []KeyView `json:"keys,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+func loadAdminSecrets() {
+	adminToken = readSecret(conf.Admin.Token, conf.Admin.TokenFile)
+	if adminToken == "" {
+		logging.Default().Warn("admin API disabled", logging.Fields{"reason": "no admin token", "file": conf.Admin.TokenFile})
+	}
+
+	c, err := keycrypt.New([]byte(readSecret(conf.Admin.MasterKey, conf.Admin.MasterKeyFile)))
+	if err != nil {
+		logging.Default().Warn("API keys cannot be added", logging.Fields{"reason": "no master key", "file": conf.Admin.MasterKeyFile, "error": err})
+		return
+	}
+	keyCipher = c
+}
+
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminToken == "" {
+			writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Admin API is not configured.", "")
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, r, http.StatusUnauthorized, errCodeUnauthorized, "Missing or invalid admin token.", "")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func listKeys(w http.ResponseWriter, r *http.Request) {
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	keys, err := repo.ListKeys(readCtx, r.URL.Query().Get("name"))
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error listing keys.", "")
+		return
+	}
+
+	resp := KeyResponse{Success: true, Message: "OK", Keys: make([]KeyView, 0, len(keys)), RequestID: requestID(r)}
+	for i := range keys {
+		resp.Keys = append(resp.Keys, keyView(&keys[i]))
+	}
+	writeJSON(w, r, http.StatusOK, &resp)
+}
+
+func addKey(w http.ResponseWriter, r *http.Request) {
+	if keyCipher == nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "No master key configured to encrypt API keys.", "")
+		return
+	}
+
+	var req KeyRequest
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes))
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Error reading key.", "")
+		return
+	}
+	req.Name = strings.ToLower(req.Name)
+	if _, ok := providerRegistry[req.Name]; !ok {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Unknown provider "+req.Name+".", "")
+		return
+	}
+	if req.Key == "" || req.Limit < 0 || req.WindowSeconds < 0 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "A key is required and limit and windowSeconds cannot be negative.", "")
+		return
+	}
+
+	encrypted, err := keyCipher.Encrypt(req.Key)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, errCodeInternal, "Error encrypting key.", "")
+		return
+	}
+	key := &stocksdb.Key{Name: req.Name, Key: encrypted, Limit: req.Limit, WindowSeconds: req.WindowSeconds}
+	writeCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBWrite)
+	err = repo.InsertKey(writeCtx, key)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error saving key.", "")
+		return
+	}
+
+	view := keyView(key)
+	writeJSON(w, r, http.StatusCreated, &KeyResponse{Success: true, Message: "Key " + view.ID + " added", Key: &view, RequestID: requestID(r)})
+}
+
+func disableKey(w http.ResponseWriter, r *http.Request) {
+	setKeyDisabled(w, r, true)
+}
+
+func enableKey(w http.ResponseWriter, r *http.Request) {
+	setKeyDisabled(w, r, false)
+}
+
+func setKeyDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	id := mux.Vars(r)["id"]
+	writeCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBWrite)
+	found, err := repo.SetKeyDisabled(writeCtx, id, disabled)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error updating key "+id+".", "")
+		return
+	}
+	if !found {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Key "+id+" not found.", "")
+		return
+	}
+
+	state := "enabled"
+	if disabled {
+		state = "disabled"
+	}
+	writeJSON(w, r, http.StatusOK, &Response{true, "Key " + id + " " + state, requestID(r)})
+}
+
+func deleteKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	writeCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBWrite)
+	deleted, err := repo.DeleteKey(writeCtx, id)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error deleting key "+id+".", "")
+		return
+	}
+	if !deleted {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Key "+id+" not found.", "")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &Response{true, "Key " + id + " deleted", requestID(r)})
+}
+
+func keyView(k *stocksdb.Key) KeyView {
+	v := KeyView{
+		ID:            k.ID.Hex(),
+		Name:          k.Name,
+		Key:           "****",
+		Limit:         k.Limit,
+		WindowSeconds: k.WindowSeconds,
+		Used:          k.Used,
+		Exhausted:     k.Exhausted,
+		Disabled:      k.Disabled,
+		Encrypted:     keycrypt.IsEncrypted(k.Key),
+	}
+	if !k.ResetAt.IsZero() {
+		resetAt := k.ResetAt
+		v.ResetAt = &resetAt
+	}
+	if plain, err := keyCipher.Decrypt(k.Key); err == nil && len(plain) >= 12 {
+		v.Key = "****" + plain[len(plain)-4:]
+	}
+	return v
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"stocks/stocksdb"
+	"testing"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	prevRepo, prevToken := repo, adminToken
+	repo, adminToken = stocksdb.NewMemoryRepository(), "s3cret"
+	t.Cleanup(func() { repo, adminToken = prevRepo, prevToken })
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"bearer token", "Bearer s3cret", http.StatusOK},
+		{"no header", "", http.StatusUnauthorized},
+		{"token without scheme", "s3cret", http.StatusUnauthorized},
+		{"other scheme", "Basic s3cret", http.StatusUnauthorized},
+		{"wrong token", "Bearer s3cre", http.StatusUnauthorized},
+		{"empty bearer token", "Bearer ", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/keys", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			newRouter().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("got %d %s, want %d", rec.Code, rec.Body.String(), tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"stocks/stocksdb"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+func deleteStock(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(mux.Vars(r)["ticker"])
+	reason := r.URL.Query().Get("reason")
+
+	writeCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBWrite)
+	archived, err := repo.ArchiveStock(writeCtx, ticker, reason)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error archiving stock "+ticker+".", ticker)
+		return
+	}
+	if !archived {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Stock "+ticker+" not found.", ticker)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &Response{true, "Stock " + ticker + " archived", requestID(r)})
+}
+
+func restoreStock(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(mux.Vars(r)["ticker"])
+
+	writeCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBWrite)
+	restored, err := repo.RestoreStock(writeCtx, ticker)
+	cancel()
+	if err == stocksdb.ErrStockExists {
+		writeError(w, r, http.StatusConflict, errCodeConflict, "Stock "+ticker+" already exists.", ticker)
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Error restoring stock "+ticker+".", ticker)
+		return
+	}
+	if !restored {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Stock "+ticker+" is not archived.", ticker)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &Response{true, "Stock " + ticker + " restored", requestID(r)})
+}
@@ -0,0 +1,142 @@
+package breaker
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the breaker rejects calls.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	}
+	return "closed"
+}
+
+// Breaker guards calls to one dependency. It opens after Threshold
+// consecutive failures and rejects calls until CoolDown has passed. Then it
+// lets a single call through: success closes it, failure opens it again.
+type Breaker struct {
+	name      string
+	threshold int
+	coolDown  time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func New(name string, threshold int, coolDown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{name: name, threshold: threshold, coolDown: coolDown}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow returns an error wrapping ErrOpen when the call must not be made.
+// Every allowed call has to be followed by Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && time.Since(b.openedAt) >= b.coolDown {
+		b.state = HalfOpen
+		b.probing = false
+	}
+	switch b.state {
+	case Open:
+		return &openError{b.name, b.coolDown - time.Since(b.openedAt)}
+	case HalfOpen:
+		if b.probing {
+			return &openError{b.name, 0}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = time.Now()
+	}
+	b.probing = false
+}
+
+// Ignore ends an allowed call that neither succeeded nor failed, e.g. one
+// cancelled by the client.
+func (b *Breaker) Ignore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Status is a snapshot of a breaker for health reports.
+type Status struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+}
+
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == Open && time.Since(b.openedAt) >= b.coolDown {
+		state = HalfOpen
+	}
+	return Status{b.name, state.String(), b.failures}
+}
+
+type openError struct {
+	name    string
+	retryIn time.Duration
+}
+
+func (e *openError) Error() string {
+	msg := e.name + " " + ErrOpen.Error()
+	if e.retryIn > 0 {
+		msg += ", try again in " + strconv.Itoa(int(e.retryIn.Seconds()+1)) + "s"
+	}
+	return msg
+}
+
+func (e *openError) Is(target error) bool {
+	return target == ErrOpen
+}
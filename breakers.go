@@ -0,0 +1,19 @@
+package main
+
+import (
+	"stocks/breaker"
+	"stocks/stocksdb"
+	"stocks/yahoodata"
+)
+
+// setupBreakers opens the breaker of Yahoo or the competitors service for
+// breaker.coolDown after breaker.threshold consecutive failures.
+func setupBreakers() {
+	threshold, coolDown := conf.Breaker.Threshold, conf.Breaker.CoolDown
+	yahoodata.Breaker = breaker.New("yahoo", threshold, coolDown)
+	stocksdb.CompetitorsBreaker = breaker.New("competitors", threshold, coolDown)
+}
+
+func breakerStatuses() []breaker.Status {
+	return []breaker.Status{yahoodata.Breaker.Status(), stocksdb.CompetitorsBreaker.Status()}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type BulkResponse struct {
+	Success   bool           `json:"status"`
+	Message   string         `json:"message"`
+	Results   []importResult `json:"results,omitempty"`
+	Jobs      []jobRef       `json:"jobs,omitempty"`
+	RequestID string         `json:"requestId,omitempty"`
+}
+
+const maxBulkBodyBytes = 1 << 20
+
+func importStocks(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Error reading request body.", "")
+		return
+	}
+
+	tickers, err := parseTickers(body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Error parsing tickers: "+err.Error(), "")
+		return
+	}
+	if len(tickers) == 0 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "No tickers given.", "")
+		return
+	}
+
+	provider := r.URL.Query().Get("provider")
+	providers, err := newProviderSet(provider)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Unknown provider "+provider+".", "")
+		return
+	}
+
+	if isAsync(r) {
+		enqueueImports(w, r, tickers, providers.requested)
+		return
+	}
+
+	results := importTickers(tickers, func(ticker string) importResult {
+		return importTicker(r.Context(), providers, ticker)
+	})
+
+	writeBulkResults(w, r, "Imported", results)
+}
+
+func writeBulkResults(w http.ResponseWriter, r *http.Request, verb string, results []importResult) {
+	imported := 0
+	for _, res := range results {
+		if res.imported() {
+			imported++
+		}
+	}
+	message := verb + " " + strconv.Itoa(imported) + " of " + strconv.Itoa(len(results)) + " stocks"
+	writeJSON(w, r, http.StatusOK, &BulkResponse{Success: imported == len(results), Message: message, Results: results, RequestID: requestID(r)})
+}
+
+func enqueueImports(w http.ResponseWriter, r *http.Request, tickers []string, provider string) {
+	var jobs []jobRef
+	for _, ticker := range tickers {
+		job, err := enqueueImport(r.Context(), ticker, provider)
+		if err != nil {
+			message := "Error queueing import of " + ticker + ". Queued " + strconv.Itoa(len(jobs)) + " of " + strconv.Itoa(len(tickers)) + " stocks"
+			writeDBError(w, r, message, ticker)
+			return
+		}
+		jobs = append(jobs, jobRef{ticker, job.ID.Hex()})
+	}
+
+	message := "Queued " + strconv.Itoa(len(jobs)) + " stocks for import"
+	writeJSON(w, r, http.StatusAccepted, &BulkResponse{Success: true, Message: message, Jobs: jobs, RequestID: requestID(r)})
+}
+
+// importTickers runs fn for every ticker using at most IMPORTCONCURRENCY
+// parallel calls. Results keep the order of tickers.
+func importTickers(tickers []string, fn func(ticker string) importResult) []importResult {
+	workers := conf.Import.Concurrency
+	if workers > len(tickers) {
+		workers = len(tickers)
+	}
+
+	results := make([]importResult, len(tickers))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range next {
+				results[idx] = fn(tickers[idx])
+			}
+		}()
+	}
+	for idx := range tickers {
+		next <- idx
+	}
+	close(next)
+	wg.Wait()
+
+	return results
+}
+
+// parseTickers accepts either a JSON array of tickers or a newline-delimited
+// list. Tickers are upper-cased and duplicates are dropped.
+func parseTickers(body []byte) ([]string, error) {
+	var raw []string
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for scanner.Scan() {
+			raw = append(raw, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var tickers []string
+	for _, t := range raw {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		tickers = append(tickers, t)
+	}
+	return tickers, nil
+}
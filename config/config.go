@@ -0,0 +1,345 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting of the API. Each leaf field can be set in the
+// YAML file under its yaml path, in the environment variable named by its
+// env tag and with a flag named after the dotted yaml path, e.g.
+// -mongodb.server. Flags win over the environment, which wins over the file.
+// Fields tagged secret are redacted by Print.
+type Config struct {
+	Port     string `yaml:"port" env:"PORT"`
+	LogLevel string `yaml:"logLevel" env:"LOGLEVEL"`
+
+	MongoDB struct {
+		Server   string `yaml:"server" env:"MONGODBSERVERNAME"`
+		Port     string `yaml:"port" env:"MONGODBSERVERPORT"`
+		PoolSize uint64 `yaml:"poolSize" env:"MONGODBPOOLSIZE"`
+		// User and Password take precedence over the secret files.
+		User         string `yaml:"user" env:"MONGODBUSER"`
+		Password     string `yaml:"password" env:"MONGODBPASSWORD" secret:"true"`
+		UserFile     string `yaml:"userFile" env:"MONGODBUSERFILE"`
+		PasswordFile string `yaml:"passwordFile" env:"MONGODBPASSWORDFILE"`
+	} `yaml:"mongodb"`
+
+	Competitors struct {
+		Name string `yaml:"name" env:"COMPETITORS_NAME"`
+		Port string `yaml:"port" env:"COMPETITORS_PORT"`
+	} `yaml:"competitors"`
+
+	Providers struct {
+		Default          string `yaml:"default" env:"DEFAULTPROVIDER"`
+		ByExchange       string `yaml:"byExchange" env:"PROVIDERSBYEXCHANGE"`
+		YahooFixturesDir string `yaml:"yahooFixturesDir" env:"YAHOOFIXTURESDIR"`
+		YahooMaxAttempts int    `yaml:"yahooMaxAttempts" env:"YAHOOMAXATTEMPTS"`
+	} `yaml:"providers"`
+
+	Timeouts struct {
+		Provider      time.Duration `yaml:"provider" env:"PROVIDERTIMEOUT"`
+		DBRead        time.Duration `yaml:"dbRead" env:"DBREADTIMEOUT"`
+		DBWrite       time.Duration `yaml:"dbWrite" env:"DBWRITETIMEOUT"`
+		Competitors   time.Duration `yaml:"competitors" env:"COMPETITORSTIMEOUT"`
+		ShutdownGrace time.Duration `yaml:"shutdownGrace" env:"SHUTDOWNGRACEPERIOD"`
+	} `yaml:"timeouts"`
+
+	Breaker struct {
+		Threshold int           `yaml:"threshold" env:"BREAKERTHRESHOLD"`
+		CoolDown  time.Duration `yaml:"coolDown" env:"BREAKERCOOLDOWN"`
+	} `yaml:"breaker"`
+
+	Jobs struct {
+		Workers int `yaml:"workers" env:"JOBWORKERS"`
+	} `yaml:"jobs"`
+
+	Import struct {
+		Concurrency int `yaml:"concurrency" env:"IMPORTCONCURRENCY"`
+	} `yaml:"import"`
+
+	Admin struct {
+		// Token and MasterKey take precedence over the secret files.
+		Token         string `yaml:"token" env:"ADMINTOKEN" secret:"true"`
+		TokenFile     string `yaml:"tokenFile" env:"ADMINTOKENFILE"`
+		MasterKey     string `yaml:"masterKey" env:"KEYMASTERKEY" secret:"true"`
+		MasterKeyFile string `yaml:"masterKeyFile" env:"KEYMASTERKEYFILE"`
+	} `yaml:"admin"`
+
+	Readiness struct {
+		CheckCompetitors bool `yaml:"checkCompetitors" env:"READYCHECKCOMPETITORS"`
+	} `yaml:"readiness"`
+
+	Tracing struct {
+		// Exporter is otlp, console or none. The OTLP exporter itself is
+		// configured with the standard OTEL_EXPORTER_OTLP_* variables.
+		Exporter string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	} `yaml:"tracing"`
+}
+
+func Default() *Config {
+	c := &Config{Port: "8080", LogLevel: "info"}
+	c.MongoDB.Server = "localhost"
+	c.MongoDB.Port = "27017"
+	c.MongoDB.UserFile = "/run/secrets/stocksmongouser"
+	c.MongoDB.PasswordFile = "/run/secrets/stocksmongopassword"
+	c.Providers.YahooMaxAttempts = 3
+	c.Timeouts.Provider = 30 * time.Second
+	c.Timeouts.DBRead = 10 * time.Second
+	c.Timeouts.DBWrite = 10 * time.Second
+	c.Timeouts.Competitors = 10 * time.Second
+	c.Timeouts.ShutdownGrace = 30 * time.Second
+	c.Breaker.Threshold = 5
+	c.Breaker.CoolDown = 30 * time.Second
+	c.Jobs.Workers = 2
+	c.Import.Concurrency = 4
+	c.Admin.TokenFile = "/run/secrets/stocksadmintoken"
+	c.Admin.MasterKeyFile = "/run/secrets/stockskeymaster"
+	return c
+}
+
+// Load builds the configuration from the defaults, the YAML file given with
+// -config or CONFIGFILE, the environment and args, in increasing order of
+// precedence, and validates it against the registered providers.
+// printOnly is set by -print-config.
+func Load(args []string, providers []string) (c *Config, printOnly bool, err error) {
+	c = Default()
+	settings := c.settings()
+
+	fs := flag.NewFlagSet("stocks", flag.ContinueOnError)
+	file := fs.String("config", os.Getenv("CONFIGFILE"), "YAML configuration file")
+	fs.BoolVar(&printOnly, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		name := s.flag
+		fs.Func(name, "same as $"+s.env, func(v string) error {
+			flagValues[name] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	if *file != "" {
+		b, err := ioutil.ReadFile(*file)
+		if err != nil {
+			return nil, false, err
+		}
+		dec := yaml.NewDecoder(strings.NewReader(string(b)))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && err != io.EOF {
+			return nil, false, fmt.Errorf("%s: %w", *file, err)
+		}
+	}
+
+	var errs ValidationError
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok && v != "" {
+			if err := s.set(v); err != nil {
+				errs = append(errs, s.env+": "+err.Error())
+			}
+		}
+	}
+	for _, s := range settings {
+		if v, ok := flagValues[s.flag]; ok {
+			if err := s.set(v); err != nil {
+				errs = append(errs, "-"+s.flag+": "+err.Error())
+			}
+		}
+	}
+	if err := c.Validate(providers); err != nil {
+		errs = append(errs, err.(ValidationError)...)
+	}
+	if len(errs) > 0 {
+		return nil, false, errs
+	}
+	return c, printOnly, nil
+}
+
+// ValidationError lists every invalid setting.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e, "; ")
+}
+
+// Validate checks every setting. providers.default and the providers of
+// providers.byExchange must be among providers.
+func (c *Config) Validate(providers []string) error {
+	var errs ValidationError
+	if p, err := strconv.Atoi(c.Port); err != nil || p < 1 || p > 65535 {
+		errs = append(errs, "port must be a number between 1 and 65535")
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		errs = append(errs, "logLevel must be debug, info, warn or error")
+	}
+	if c.MongoDB.Server == "" {
+		errs = append(errs, "mongodb.server is required")
+	}
+	if p, err := strconv.Atoi(c.MongoDB.Port); err != nil || p < 1 || p > 65535 {
+		errs = append(errs, "mongodb.port must be a number between 1 and 65535")
+	}
+	if (c.Competitors.Name == "") != (c.Competitors.Port == "") {
+		errs = append(errs, "competitors.name and competitors.port must be set together")
+	}
+	errs = append(errs, c.validateProviders(providers)...)
+	if c.Providers.YahooMaxAttempts < 1 {
+		errs = append(errs, "providers.yahooMaxAttempts must be at least 1")
+	}
+	for name, d := range map[string]time.Duration{
+		"timeouts.provider":      c.Timeouts.Provider,
+		"timeouts.dbRead":        c.Timeouts.DBRead,
+		"timeouts.dbWrite":       c.Timeouts.DBWrite,
+		"timeouts.competitors":   c.Timeouts.Competitors,
+		"timeouts.shutdownGrace": c.Timeouts.ShutdownGrace,
+		"breaker.coolDown":       c.Breaker.CoolDown,
+	} {
+		if d <= 0 {
+			errs = append(errs, name+" must be positive")
+		}
+	}
+	if c.Breaker.Threshold < 1 {
+		errs = append(errs, "breaker.threshold must be at least 1")
+	}
+	if c.Jobs.Workers < 1 {
+		errs = append(errs, "jobs.workers must be at least 1")
+	}
+	if c.Import.Concurrency < 1 {
+		errs = append(errs, "import.concurrency must be at least 1")
+	}
+	switch c.Tracing.Exporter {
+	case "", "otlp", "console", "stdout", "none":
+	default:
+		errs = append(errs, "tracing.exporter must be otlp, console or none")
+	}
+
+	if len(errs) > 0 {
+		// Map iteration order is random, keep the report stable.
+		sort.Strings(errs)
+		return errs
+	}
+	return nil
+}
+
+func (c *Config) validateProviders(providers []string) []string {
+	known := make(map[string]bool)
+	for _, p := range providers {
+		known[strings.ToLower(p)] = true
+	}
+	names := append([]string(nil), providers...)
+	sort.Strings(names)
+	oneOf := " must be one of " + strings.Join(names, ", ")
+
+	var errs []string
+	if c.Providers.Default != "" && !known[strings.ToLower(c.Providers.Default)] {
+		errs = append(errs, "providers.default"+oneOf)
+	}
+	if c.Providers.ByExchange == "" {
+		return errs
+	}
+	for _, pair := range strings.Split(c.Providers.ByExchange, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			errs = append(errs, "providers.byExchange entry "+strconv.Quote(pair)+" must be exchange=provider")
+			continue
+		}
+		if !known[strings.ToLower(strings.TrimSpace(kv[1]))] {
+			errs = append(errs, "providers.byExchange provider for "+strings.TrimSpace(kv[0])+oneOf)
+		}
+	}
+	return errs
+}
+
+// Print writes the configuration as YAML with secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	for _, s := range redacted.settings() {
+		if s.secret && s.value.String() != "" {
+			s.value.SetString("REDACTED")
+		}
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&redacted); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// setting is one leaf field of Config.
+type setting struct {
+	flag   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+func (c *Config) settings() []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := prefix + strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Duration(0)) {
+				walk(v.Field(i), name+".")
+				continue
+			}
+			settings = append(settings, setting{name, f.Tag.Get("env"), f.Tag.Get("secret") == "true", v.Field(i)})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return settings
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (s setting) set(raw string) error {
+	v := s.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return errors.New("not a positive number")
+		}
+		v.SetUint(n)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("not a boolean")
+		}
+		v.SetBool(b)
+	default:
+		return errors.New("unsupported type " + v.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testProviders = []string{"yahoo", "fake"}
+
+// clearEnv unsets every configuration variable for the test; Load ignores
+// empty ones.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIGFILE", "")
+	for _, s := range Default().settings() {
+		t.Setenv(s.env, "")
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stocks.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	file := writeFile(t, `
+port: "8081"
+logLevel: debug
+mongodb:
+  server: db.file
+  poolSize: 10
+timeouts:
+  dbRead: 5s
+jobs:
+  workers: 3
+`)
+	t.Setenv("PORT", "8082")
+	t.Setenv("LOGLEVEL", "warn")
+	t.Setenv("MONGODBPOOLSIZE", "20")
+	t.Setenv("DBWRITETIMEOUT", "3s")
+	t.Setenv("READYCHECKCOMPETITORS", "true")
+
+	c, printOnly, err := Load([]string{"-config", file, "-port", "8083", "-jobs.workers=7", "-timeouts.dbWrite", "4s"}, testProviders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if printOnly {
+		t.Error("printOnly set without -print-config")
+	}
+
+	tests := []struct {
+		setting   string
+		got, want interface{}
+	}{
+		{"port from flag over env and file", c.Port, "8083"},
+		{"logLevel from env over file", c.LogLevel, "warn"},
+		{"mongodb.server from file", c.MongoDB.Server, "db.file"},
+		{"mongodb.poolSize from env", c.MongoDB.PoolSize, uint64(20)},
+		{"timeouts.dbRead from file", c.Timeouts.DBRead, 5 * time.Second},
+		{"timeouts.dbWrite from flag", c.Timeouts.DBWrite, 4 * time.Second},
+		{"jobs.workers from flag", c.Jobs.Workers, 7},
+		{"readiness.checkCompetitors from env", c.Readiness.CheckCompetitors, true},
+		{"mongodb.port default", c.MongoDB.Port, "27017"},
+		{"timeouts.provider default", c.Timeouts.Provider, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.setting, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIGFILE", writeFile(t, "breaker:\n  threshold: 9\n"))
+
+	c, _, err := Load([]string{"-print-config"}, testProviders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Breaker.Threshold != 9 {
+		t.Errorf("breaker.threshold = %d, want 9 from CONFIGFILE", c.Breaker.Threshold)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		want []string
+	}{
+		{
+			name: "unknown file key",
+			file: "mongodb:\n  sever: db\n",
+			want: []string{"field sever not found"},
+		},
+		{
+			name: "file of the wrong type",
+			file: "jobs:\n  workers: many\n",
+			want: []string{"cannot unmarshal"},
+		},
+		{
+			name: "bad environment values",
+			env:  map[string]string{"JOBWORKERS": "many", "BREAKERCOOLDOWN": "soon", "MONGODBPOOLSIZE": "-1", "READYCHECKCOMPETITORS": "maybe"},
+			want: []string{
+				"JOBWORKERS: not a number",
+				"BREAKERCOOLDOWN: time: invalid duration",
+				"MONGODBPOOLSIZE: not a positive number",
+				"READYCHECKCOMPETITORS: not a boolean",
+			},
+		},
+		{
+			name: "bad flag values",
+			args: []string{"-import.concurrency=x", "-timeouts.provider=1"},
+			want: []string{"-import.concurrency: not a number", "-timeouts.provider: time: missing unit"},
+		},
+		{
+			name: "values that fail validation",
+			env:  map[string]string{"PORT": "70000", "JOBWORKERS": "0"},
+			args: []string{"-timeouts.dbRead=-1s"},
+			want: []string{
+				"port must be a number between 1 and 65535",
+				"jobs.workers must be at least 1",
+				"timeouts.dbRead must be positive",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file)}, args...)
+			}
+
+			c, _, err := Load(args, testProviders)
+			if err == nil {
+				t.Fatalf("Load succeeded with %+v", c)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	clearEnv(t)
+	if _, _, err := Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}, testProviders); err == nil {
+		t.Error("Load succeeded without the config file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"port", func(c *Config) { c.Port = "http" }, "port must be a number between 1 and 65535"},
+		{"log level", func(c *Config) { c.LogLevel = "loud" }, "logLevel must be debug, info, warn or error"},
+		{"log level case", func(c *Config) { c.LogLevel = "WARN" }, ""},
+		{"mongodb server", func(c *Config) { c.MongoDB.Server = "" }, "mongodb.server is required"},
+		{"mongodb port", func(c *Config) { c.MongoDB.Port = "0" }, "mongodb.port must be a number between 1 and 65535"},
+		{"competitors name alone", func(c *Config) { c.Competitors.Name = "competitors" }, "competitors.name and competitors.port must be set together"},
+		{"competitors", func(c *Config) { c.Competitors.Name, c.Competitors.Port = "competitors", "8080" }, ""},
+		{"yahoo attempts", func(c *Config) { c.Providers.YahooMaxAttempts = 0 }, "providers.yahooMaxAttempts must be at least 1"},
+		{"timeout", func(c *Config) { c.Timeouts.ShutdownGrace = 0 }, "timeouts.shutdownGrace must be positive"},
+		{"breaker threshold", func(c *Config) { c.Breaker.Threshold = 0 }, "breaker.threshold must be at least 1"},
+		{"breaker cool down", func(c *Config) { c.Breaker.CoolDown = -time.Second }, "breaker.coolDown must be positive"},
+		{"import concurrency", func(c *Config) { c.Import.Concurrency = 0 }, "import.concurrency must be at least 1"},
+		{"tracing exporter", func(c *Config) { c.Tracing.Exporter = "jaeger" }, "tracing.exporter must be otlp, console or none"},
+		{"default provider", func(c *Config) { c.Providers.Default = "Yahoo" }, ""},
+		{"unknown default provider", func(c *Config) { c.Providers.Default = "google" }, "providers.default must be one of fake, yahoo"},
+		{"providers by exchange", func(c *Config) { c.Providers.ByExchange = "NasdaqGS=yahoo, NYSE = Fake" }, ""},
+		{"unknown provider by exchange", func(c *Config) { c.Providers.ByExchange = "NasdaqGS=yahoo,NYSE=google" }, "providers.byExchange provider for NYSE must be one of fake, yahoo"},
+		{"malformed provider by exchange", func(c *Config) { c.Providers.ByExchange = "NYSE" }, `providers.byExchange entry "NYSE" must be exchange=provider`},
+		{"provider without exchange", func(c *Config) { c.Providers.ByExchange = "=yahoo" }, `providers.byExchange entry "=yahoo" must be exchange=provider`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.change(c)
+			err := c.Validate(testProviders)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateListsEveryError(t *testing.T) {
+	c := Default()
+	c.Jobs.Workers = 0
+	c.Port = ""
+	c.Providers.Default = "google"
+
+	err := c.Validate(testProviders)
+	verr, ok := err.(ValidationError)
+	if !ok || len(verr) != 3 {
+		t.Fatalf("Validate = %#v, want three errors", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	c := Default()
+	c.Admin.Token = "s3cret"
+	c.MongoDB.Password = "hunter2"
+
+	var sb strings.Builder
+	if err := c.Print(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	if strings.Contains(out, "s3cret") || strings.Contains(out, "hunter2") || !strings.Contains(out, "REDACTED") {
+		t.Errorf("secrets not redacted:\n%s", out)
+	}
+	if c.Admin.Token != "s3cret" {
+		t.Error("Print changed the configuration")
+	}
+}
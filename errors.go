@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"stocks/logging"
+)
+
+type ErrorResponse struct {
+	Success   bool   `json:"status"`
+	Message   string `json:"message"`
+	Code      string `json:"code"`
+	Ticker    string `json:"ticker,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Attempts  int    `json:"attempts,omitempty"`
+}
+
+const (
+	errCodeBadRequest       = "bad_request"
+	errCodeUnauthorized     = "unauthorized"
+	errCodeNotFound         = "not_found"
+	errCodeConflict         = "conflict"
+	errCodeMethodNotAllowed = "method_not_allowed"
+	errCodeUpstream         = "upstream_error"
+	errCodeUnavailable      = "unavailable"
+	errCodeInternal         = "internal_error"
+)
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message, ticker string) {
+	writeErrorResponse(w, status, &ErrorResponse{Message: message, Code: code, Ticker: ticker, RequestID: requestID(r)})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, resp *ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logging.Default().Warn("writing response failed", logging.Fields{"requestId": resp.RequestID, "error": err})
+	}
+}
+
+// writeDBError answers a failed database call. All handlers use it so a
+// database outage looks the same to clients on every endpoint.
+func writeDBError(w http.ResponseWriter, r *http.Request, message, ticker string) {
+	writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, message, ticker)
+}
+
+// writeJSON encodes v before writing the status, so a value that cannot be
+// encoded is answered with a 500 instead of an empty 200.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("encoding response failed", logging.Fields{"path": r.URL.Path, "error": err})
+		writeError(w, r, http.StatusInternalServerError, errCodeInternal, "Error encoding response.", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		logging.FromContext(r.Context()).Warn("writing response failed", logging.Fields{"path": r.URL.Path, "error": err})
+	}
+}
+
+// writeImportError answers a failed import with the status matching the
+// result: 404 for unknown tickers, 503 when no provider can be used and 502
+// for provider failures.
+func writeImportError(w http.ResponseWriter, r *http.Request, res importResult) {
+	status, code := http.StatusInternalServerError, errCodeInternal
+	switch res.Status {
+	case importNotFound:
+		status, code = http.StatusNotFound, errCodeNotFound
+	case importUnavailable:
+		status, code = http.StatusServiceUnavailable, errCodeUnavailable
+	case importProviderError:
+		status, code = http.StatusBadGateway, errCodeUpstream
+	}
+	writeErrorResponse(w, status, &ErrorResponse{Message: res.Message, Code: code, Ticker: res.Ticker, RequestID: requestID(r), Attempts: res.Attempts})
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, errCodeNotFound, "No route for "+r.URL.Path+".", "")
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Method "+r.Method+" not allowed on "+r.URL.Path+".", "")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"stocks/stocksdb"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type HistoryResponse struct {
+	Success   bool                    `json:"status"`
+	Message   string                  `json:"message"`
+	Ticker    string                  `json:"ticker"`
+	Field     string                  `json:"field"`
+	Series    []stocksdb.HistoryPoint `json:"series"`
+	RequestID string                  `json:"requestId,omitempty"`
+}
+
+func getStockHistory(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(mux.Vars(r)["ticker"])
+	params := r.URL.Query()
+
+	field := strings.ToLower(params.Get("field"))
+	if !stocksdb.HistoryField(field) {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "field must be a numeric stock field or recommtrend.", ticker)
+		return
+	}
+
+	from, _, err := parseTimeParam(params.Get("from"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid from date.", ticker)
+		return
+	}
+	to, dateOnly, err := parseTimeParam(params.Get("to"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid to date.", ticker)
+		return
+	}
+	if dateOnly {
+		to = to.AddDate(0, 0, 1)
+	} else if !to.IsZero() {
+		to = to.Add(time.Nanosecond)
+	}
+
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	series, err := repo.GetHistory(readCtx, ticker, field, from, to)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error getting history of "+ticker+".", ticker)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &HistoryResponse{true, "OK", ticker, field, series, requestID(r)})
+}
+
+// parseTimeParam accepts RFC 3339 timestamps and plain dates. It also reports
+// whether the value was a plain date so callers can make ranges inclusive.
+func parseTimeParam(s string) (time.Time, bool, error) {
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	return t, err == nil, err
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"stocks/logging"
+	"stocks/stocksdb"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type JobResponse struct {
+	Success   bool          `json:"status"`
+	Message   string        `json:"message"`
+	Job       *stocksdb.Job `json:"job,omitempty"`
+	RequestID string        `json:"requestId,omitempty"`
+}
+
+type jobRef struct {
+	Ticker string `json:"ticker"`
+	JobID  string `json:"jobId"`
+}
+
+const jobMaxAttempts = 3
+const jobRetryDelay = 30 * time.Second
+const jobPollInterval = 5 * time.Second
+const jobStaleAfter = 15 * time.Minute
+
+var jobWakeup = make(chan struct{}, 1)
+
+// jobsStop is closed on shutdown. Workers finish their current job and
+// exit.
+var jobsStop = make(chan struct{})
+var jobsDone sync.WaitGroup
+
+// jobsCtx is the context of the running jobs. cancelJobs aborts them when
+// they do not finish within the shutdown grace period.
+var jobsCtx, cancelJobs = context.WithCancel(context.Background())
+
+func isAsync(r *http.Request) bool {
+	async, _ := strconv.ParseBool(r.URL.Query().Get("async"))
+	return async
+}
+
+func enqueueImport(ctx context.Context, ticker, provider string) (*stocksdb.Job, error) {
+	writeCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBWrite)
+	job, err := repo.NewJob(writeCtx, ticker, provider)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case jobWakeup <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func getJob(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	job, err := repo.GetJob(readCtx, id)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error getting job "+id+".", "")
+		return
+	}
+	if job == nil {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Job "+id+" not found.", "")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &JobResponse{true, "OK", job, requestID(r)})
+}
+
+func startJobWorkers() {
+	n := conf.Jobs.Workers
+
+	writeCtx, cancel := context.WithTimeout(context.Background(), conf.Timeouts.DBWrite)
+	requeued, err := repo.RequeueStaleJobs(writeCtx, jobStaleAfter)
+	cancel()
+	if err != nil {
+		logging.Default().Error("requeueing stale jobs failed", logging.Fields{"error": err})
+	} else if requeued > 0 {
+		logging.Default().Info("requeued stale jobs", logging.Fields{"count": requeued})
+	}
+
+	jobsDone.Add(n)
+	for i := 0; i < n; i++ {
+		go jobWorker()
+	}
+}
+
+// stopJobWorkers stops the workers from claiming jobs and waits for the
+// running ones until ctx is done. Jobs still running then are cancelled and
+// put back in the queue before it returns, so no job is left in the running
+// state for the next start.
+func stopJobWorkers(ctx context.Context) error {
+	close(jobsStop)
+
+	done := make(chan struct{})
+	go func() {
+		jobsDone.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	cancelJobs()
+	select {
+	case <-done:
+	case <-time.After(closeTimeout):
+	}
+	return ctx.Err()
+}
+
+func jobWorker() {
+	defer jobsDone.Done()
+
+	ctx := jobsCtx
+	for {
+		select {
+		case <-jobsStop:
+			return
+		default:
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBWrite)
+		job, err := repo.ClaimJob(writeCtx)
+		cancel()
+		if err != nil {
+			logging.Default().Error("claiming job failed", logging.Fields{"error": err})
+		}
+		if job == nil {
+			select {
+			case <-jobWakeup:
+			case <-time.After(jobPollInterval):
+			case <-jobsStop:
+				return
+			}
+			continue
+		}
+
+		logger := logging.Default().With(logging.Fields{"jobId": job.ID.Hex()})
+		runJob(logging.NewContext(ctx, logger), job)
+	}
+}
+
+func runJob(ctx context.Context, job *stocksdb.Job) {
+	var res importResult
+
+	providers, err := newProviderSet(job.Provider)
+	if err != nil {
+		res = importResult{Ticker: job.Ticker, Status: importProviderError, Message: "Unknown provider " + job.Provider + "."}
+	} else {
+		res = importTicker(ctx, providers, job.Ticker)
+	}
+
+	// The job state is written even when shutdown cancelled ctx. A job cut
+	// off by shutdown goes back to the queue without using up an attempt.
+	writeCtx, cancel := context.WithTimeout(logging.NewContext(context.Background(), logging.FromContext(ctx)), conf.Timeouts.DBWrite)
+	defer cancel()
+	switch {
+	case ctx.Err() != nil && !res.imported():
+		err = repo.ReleaseJob(writeCtx, job.ID)
+	case res.imported():
+		err = repo.FinishJob(writeCtx, job.ID, stocksdb.JobSucceeded, res.Status, res.Message, "")
+	case (res.Status == importProviderError || res.Status == importUnavailable) && job.Attempts < jobMaxAttempts:
+		runAfter := time.Now().Add(time.Duration(job.Attempts) * jobRetryDelay)
+		err = repo.RetryJob(writeCtx, job.ID, res.Message, runAfter)
+	default:
+		err = repo.FinishJob(writeCtx, job.ID, stocksdb.JobFailed, res.Status, "", res.Message)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("updating job failed", logging.Fields{"ticker": job.Ticker, "error": err})
+	}
+}
@@ -0,0 +1,77 @@
+package keycrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// prefix marks encrypted values so keys stored in plain text before
+// encryption was introduced keep working.
+const prefix = "enc:v1:"
+
+var ErrNoMasterKey = errors.New("no master key configured")
+
+// Cipher encrypts API keys with AES-256-GCM. The AES key is the SHA-256 of
+// the master key, so any secret of reasonable length can be used.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func New(masterKey []byte) (*Cipher, error) {
+	if len(masterKey) == 0 {
+		return nil, ErrNoMasterKey
+	}
+	sum := sha256.Sum256(masterKey)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead}, nil
+}
+
+func (c *Cipher) Encrypt(plain string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns values without the encryption prefix unchanged. A nil
+// Cipher can only do that.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", ErrNoMasterKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("encrypted key is too short")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
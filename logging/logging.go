@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "debug"
+	case Warn:
+		return "warn"
+	case Error:
+		return "error"
+	}
+	return "info"
+}
+
+// ParseLevel accepts debug, info, warn and error and falls back to info.
+func ParseLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return Debug
+	case "warn", "warning":
+		return Warn
+	case "error":
+		return Error
+	}
+	return Info
+}
+
+type Fields map[string]interface{}
+
+var (
+	mu       sync.Mutex
+	out      io.Writer = os.Stderr
+	minLevel           = Info
+)
+
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	minLevel = l
+}
+
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Logger writes one JSON object per line with time, level, msg and its
+// fields. The zero value has no fields.
+type Logger struct {
+	fields Fields
+}
+
+// With returns a logger that adds fields to every entry.
+func (l *Logger) With(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{merged}
+}
+
+func (l *Logger) Debug(msg string, fields Fields) { l.log(Debug, msg, fields) }
+func (l *Logger) Info(msg string, fields Fields)  { l.log(Info, msg, fields) }
+func (l *Logger) Warn(msg string, fields Fields)  { l.log(Warn, msg, fields) }
+func (l *Logger) Error(msg string, fields Fields) { l.log(Error, msg, fields) }
+
+func (l *Logger) log(level Level, msg string, fields Fields) {
+	mu.Lock()
+	defer mu.Unlock()
+	if level < minLevel {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(l.fields)+len(fields)+3)
+	for k, v := range l.fields {
+		entry[k] = v
+	}
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		entry[k] = v
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"time": entry["time"].(string), "level": "error", "msg": "unloggable entry: " + msg})
+	}
+	out.Write(append(b, '\n'))
+}
+
+var root = &Logger{}
+
+type loggerKey struct{}
+
+// NewContext returns a context carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger of ctx, or one without fields.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return l
+	}
+	return root
+}
+
+// Default returns the logger without fields used outside of requests.
+func Default() *Logger {
+	return root
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"stocks/logging"
+	"sync"
+	"time"
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// withAccessLog gives every request a logger tagged with its request ID and
+// logs the request once it is served. It has to run inside withRequestID.
+func withAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		logger := logging.Default().With(logging.Fields{"requestId": requestID(r)})
+		rec := &statusRecorder{w, http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(logging.NewContext(r.Context(), logger)))
+
+		fields := logging.Fields{
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"status":     rec.status,
+			"durationMs": time.Since(start).Milliseconds(),
+			"remoteAddr": r.RemoteAddr,
+			"userAgent":  r.UserAgent(),
+		}
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			fields["forwardedFor"] = fwd
+		}
+		if rec.status >= http.StatusInternalServerError {
+			logger.Warn("request", fields)
+		} else {
+			logger.Info("request", fields)
+		}
+	})
+}
+
+// importLog collects the fields of the one entry logged per import, like
+// the duration of each stage.
+type importLog struct {
+	mu     sync.Mutex
+	fields logging.Fields
+}
+
+type importLogKey struct{}
+
+func withImportLog(ctx context.Context) (context.Context, *importLog) {
+	il := &importLog{fields: logging.Fields{}}
+	return context.WithValue(ctx, importLogKey{}, il), il
+}
+
+// importLogFrom returns nil outside of importTicker, which the methods of
+// importLog accept.
+func importLogFrom(ctx context.Context) *importLog {
+	il, _ := ctx.Value(importLogKey{}).(*importLog)
+	return il
+}
+
+func (il *importLog) set(key string, value interface{}) {
+	if il == nil {
+		return
+	}
+	il.mu.Lock()
+	defer il.mu.Unlock()
+	il.fields[key] = value
+}
+
+// stage records the time since start as <name>Ms.
+func (il *importLog) stage(name string, start time.Time) {
+	il.set(name+"Ms", time.Since(start).Milliseconds())
+}
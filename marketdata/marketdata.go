@@ -0,0 +1,263 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by a provider that does not know the ticker.
+var ErrNotFound = errors.New("ticker not found")
+
+// ErrNoKey is returned when a provider has no API key with quota left.
+var ErrNoKey = errors.New("no API key with remaining quota")
+
+// APIKey is one key handed out by a KeyPool.
+type APIKey struct {
+	ID    string
+	Value string
+}
+
+// KeyPool hands out the API keys of a provider. Acquire counts one fetch
+// against the returned key and returns ErrNoKey when all keys are used up.
+// Exhausted takes a key out of rotation after the vendor rejected it for
+// quota reasons.
+type KeyPool interface {
+	Acquire(ctx context.Context) (*APIKey, error)
+	Exhausted(ctx context.Context, key *APIKey) error
+}
+
+// FetchError is returned by a provider that made Attempts requests before
+// giving up with Err.
+type FetchError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Attempts returns the number of requests recorded in err, or 0 when err
+// is not a FetchError.
+func Attempts(err error) int {
+	var fetchErr *FetchError
+	if errors.As(err, &fetchErr) {
+		return fetchErr.Attempts
+	}
+	return 0
+}
+
+// FundamentalsProvider fetches the fundamentals of one ticker from a market
+// data vendor and normalizes them into Fundamentals.
+type FundamentalsProvider interface {
+	Name() string
+	Fetch(ctx context.Context, ticker string) (*Fundamentals, error)
+}
+
+// Int and Float hold a numeric value together with the vendor formatted
+// display string, e.g. 2.5e12 and "2.5T".
+type Int struct {
+	Value   int64
+	Display string
+}
+
+type Float struct {
+	Value   float64
+	Display string
+}
+
+// Fundamentals is the provider neutral view of a company. Dates are
+// formatted as 2006-01-02 and statement lists are ordered newest first.
+type Fundamentals struct {
+	Symbol   string
+	Name     string
+	Exchange string
+	Currency string
+
+	Profile        Profile
+	Recommendation Recommendation
+	KeyStatistics  KeyStatistics
+	Summary        Summary
+	NextEarnings   Earnings
+	Financial      Financial
+	Growth5y       Float
+
+	CashFlow          []CashFlowStatement
+	CashFlowQuarterly []CashFlowStatement
+	Income            []IncomeStatement
+	IncomeQuarterly   []IncomeStatement
+	Balance           []BalanceSheet
+	BalanceQuarterly  []BalanceSheet
+
+	// Raw is the unmodified vendor payload, kept for archiving.
+	Raw []byte
+	// Attempts is the number of requests the provider made, 0 when the
+	// data was not fetched from the vendor.
+	Attempts int
+}
+
+type Profile struct {
+	Address   string
+	City      string
+	Country   string
+	Industry  string
+	Sector    string
+	Employees int64
+}
+
+// Recommendation is the analyst recommendation count of the current month.
+type Recommendation struct {
+	StrongBuy  int64
+	Buy        int64
+	Hold       int64
+	Sell       int64
+	StrongSell int64
+}
+
+type KeyStatistics struct {
+	Beta                    Float
+	EnterpriseValue         Int
+	ForwardPE               Float
+	ProfitMargins           Float
+	FloatShares             Int
+	SharesOutstanding       Int
+	SharesShort             Int
+	HeldPercentInsiders     Float
+	HeldPercentInstitutions Float
+	ShortRatio              Float
+	ShortPercentOfFloat     Float
+	BookValue               Float
+	PriceToBook             Float
+	LastFiscalYearEnd       string
+	MostRecentQuarter       string
+	NetIncomeToCommon       Int
+	TrailingEps             Float
+	ForwardEps              Float
+	PegRatio                Float
+	LastSplitFactor         string
+	LastSplitDate           string
+	EnterpriseToRevenue     Float
+	EnterpriseToEbitda      Float
+	WeekChange52            Float
+}
+
+type Summary struct {
+	ExDividendDate string
+	DividendRate   Float
+	DividendYield  Float
+	PayoutRatio    Float
+	TrailingPE     Float
+	MarketCap      Int
+}
+
+type Earnings struct {
+	Dates           []string
+	EarningsAverage Float
+	EarningsLow     Float
+	EarningsHigh    Float
+	RevenueAverage  Int
+	RevenueLow      Int
+	RevenueHigh     Int
+}
+
+type Financial struct {
+	CurrentPrice      Float
+	TargetHighPrice   Float
+	TargetLowPrice    Float
+	TargetMedianPrice Float
+	RecommendationKey string
+	TotalCash         Int
+	TotalCashPerShare Float
+	Ebitda            Int
+	TotalDebt         Int
+	QuickRatio        Float
+	CurrentRatio      Float
+	TotalRevenue      Int
+	RevenuePerShare   Float
+	ReturnOnAssets    Float
+	ReturnOnEquity    Float
+	GrossProfits      Int
+	FreeCashflow      Int
+	OperatingCashflow Int
+	GrossMargins      Float
+	EbitdaMargins     Float
+	OperatingMargins  Float
+}
+
+type CashFlowStatement struct {
+	EndDate                  string
+	CapEx                    Int
+	ChangeCash               Int
+	ChangeAccountReceivables Int
+	ChangeInventory          Int
+	ChangeLiabilities        Int
+	ChangeNetIncome          Int
+	Depreciation             Int
+	EffectExchangeRate       Int
+	Investments              Int
+	NetBorrowings            Int
+	NetIncome                Int
+	OtherCashFinancing       Int
+	OtherCashInvesting       Int
+	RepurchaseStock          Int
+	TotalCashInvesting       Int
+	TotalCashFinancing       Int
+	TotalCashOperating       Int
+}
+
+type IncomeStatement struct {
+	EndDate                      string
+	TotalRevenue                 Int
+	CostOfRevenue                Int
+	GrossProfit                  Int
+	ResearchDevelopment          Int
+	SellingGeneralAdministrative Int
+	NonRecurring                 Int
+	OtherOperatingExpenses       Int
+	TotalOperatingExpenses       Int
+	OperatingIncome              Int
+	TotalOtherIncomeExpenseNet   Int
+	Ebit                         Int
+	InterestExpense              Int
+	IncomeBeforeTax              Int
+	IncomeTaxExpense             Int
+	MinorityInterest             Int
+	NetIncomeFromContinuingOps   Int
+	DiscontinuedOperations       Int
+	ExtraordinaryItems           Int
+	EffectOfAccountingCharges    Int
+	OtherItems                   Int
+	NetIncome                    Int
+	NetIncomeCommonShares        Int
+}
+
+type BalanceSheet struct {
+	EndDate                 string
+	Cash                    Int
+	ShortTermInvestments    Int
+	NetReceivables          Int
+	Inventory               Int
+	OtherCurrentAssets      Int
+	TotalCurrentAssets      Int
+	LongTermInvestments     Int
+	PropertyPlantEquipment  Int
+	OtherAssets             Int
+	TotalAssets             Int
+	AccountsPayable         Int
+	ShortLongTermDebt       Int
+	OtherCurrentLiab        Int
+	LongTermDebt            Int
+	OtherLiab               Int
+	TotalCurrentLiabilities Int
+	TotalLiab               Int
+	CommonStock             Int
+	RetainedEarnings        Int
+	TreasuryStock           Int
+	OtherStockholderEquity  Int
+	TotalStockholderEquity  Int
+	NetTangibleAssets       Int
+}
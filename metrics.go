@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"stocks/stocksdb"
+	"stocks/yahoodata"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var importsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "stocks_imports_total",
+	Help: "Imports by outcome.",
+}, []string{"outcome"})
+
+var yahooRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "stocks_yahoo_request_duration_seconds",
+	Help:    "Duration of Yahoo fetches including retries, by final status.",
+	Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+}, []string{"status"})
+
+var mongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "stocks_mongodb_command_duration_seconds",
+	Help:    "Duration of MongoDB commands by command and outcome.",
+	Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+}, []string{"command", "outcome"})
+
+var httpInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "stocks_http_requests_in_flight",
+	Help: "HTTP requests currently being served.",
+})
+
+var keyQuotaRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "stocks_provider_key_quota_remaining",
+	Help: "Fetches left in the current window of each limited API key.",
+}, []string{"provider", "key"})
+
+func withMetrics(h http.Handler) http.Handler {
+	return promhttp.InstrumentHandlerInFlight(httpInFlight, h)
+}
+
+func metricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// recordImport counts the result under the outcome names of the metrics,
+// which call provider errors upstream_error like the error codes do.
+func recordImport(res importResult) {
+	outcome := res.Status
+	if outcome == importProviderError {
+		outcome = errCodeUpstream
+	}
+	importsTotal.WithLabelValues(outcome).Inc()
+}
+
+func recordKeyQuota(provider string, key *stocksdb.Key) {
+	if key.Limit <= 0 {
+		return
+	}
+	remaining := key.Limit - key.Used
+	if key.Exhausted || remaining < 0 {
+		remaining = 0
+	}
+	keyQuotaRemaining.WithLabelValues(provider, key.ID.Hex()).Set(float64(remaining))
+}
+
+func observeYahooRequest(d time.Duration, err error) {
+	status := "200"
+	var statusErr *yahoodata.StatusError
+	switch {
+	case err == nil:
+	case errors.As(err, &statusErr):
+		status = strconv.Itoa(statusErr.StatusCode)
+	case errors.Is(err, context.Canceled):
+		status = "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		status = "timeout"
+	default:
+		status = "error"
+	}
+	yahooRequestDuration.WithLabelValues(status).Observe(d.Seconds())
+}
+
+func mongoMetricsMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			mongoCommandDuration.WithLabelValues(e.CommandName, "ok").Observe(time.Duration(e.DurationNanos).Seconds())
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			mongoCommandDuration.WithLabelValues(e.CommandName, "error").Observe(time.Duration(e.DurationNanos).Seconds())
+		},
+	}
+}
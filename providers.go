@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"stocks/logging"
+	"stocks/marketdata"
+	"stocks/yahoodata"
+	"strings"
+	"sync"
+)
+
+type providerEntry struct {
+	newProvider func(keys marketdata.KeyPool) marketdata.FundamentalsProvider
+	// newOffline returns a provider that needs no API key, or nil when the
+	// provider is not configured to run offline.
+	newOffline func() marketdata.FundamentalsProvider
+	parse      func(body []byte) (*marketdata.Fundamentals, error)
+}
+
+var providerRegistry = map[string]providerEntry{
+	"yahoo": {
+		newProvider: func(keys marketdata.KeyPool) marketdata.FundamentalsProvider { return yahoodata.NewProvider(keys) },
+		newOffline: func() marketdata.FundamentalsProvider {
+			if conf.Providers.YahooFixturesDir == "" {
+				return nil
+			}
+			return yahoodata.NewFileProvider(conf.Providers.YahooFixturesDir)
+		},
+		parse: yahoodata.ParseFundamentals,
+	},
+}
+
+const fallbackProvider = "yahoo"
+
+func providerNames() []string {
+	names := make([]string, 0, len(providerRegistry))
+	for name := range providerRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// providerSet resolves the provider of each ticker of one request and keeps
+// the constructed providers.
+type providerSet struct {
+	requested string
+
+	mu        sync.Mutex
+	providers map[string]marketdata.FundamentalsProvider
+}
+
+// newProviderSet returns an error when requested is neither empty nor a
+// registered provider.
+func newProviderSet(requested string) (*providerSet, error) {
+	requested = strings.ToLower(requested)
+	if requested != "" {
+		if _, ok := providerRegistry[requested]; !ok {
+			return nil, errors.New("unknown provider " + requested)
+		}
+	}
+	return &providerSet{
+		requested: requested,
+		providers: make(map[string]marketdata.FundamentalsProvider),
+	}, nil
+}
+
+// forTicker returns the provider to use for ticker and its name. The
+// provider is nil when it is not registered.
+func (ps *providerSet) forTicker(ctx context.Context, ticker string) (marketdata.FundamentalsProvider, string) {
+	name := ps.requested
+	if name == "" {
+		name = providerNameForTicker(ctx, ticker)
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if p, ok := ps.providers[name]; ok {
+		return p, name
+	}
+
+	entry, ok := providerRegistry[name]
+	if !ok {
+		return nil, name
+	}
+	if entry.newOffline != nil {
+		if p := entry.newOffline(); p != nil {
+			ps.providers[name] = p
+			return p, name
+		}
+	}
+	p := entry.newProvider(keyPool{name})
+	ps.providers[name] = p
+	return p, name
+}
+
+// keyPool hands out the keys stored for a provider in the keys collection.
+type keyPool struct {
+	name string
+}
+
+func (kp keyPool) Acquire(ctx context.Context) (*marketdata.APIKey, error) {
+	writeCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBWrite)
+	defer cancel()
+
+	key, err := repo.AcquireKey(writeCtx, kp.name)
+	if err != nil {
+		logging.FromContext(ctx).Error("getting API key failed", logging.Fields{"provider": kp.name, "error": err})
+		return nil, fmt.Errorf("%w: %v", marketdata.ErrNoKey, err)
+	}
+	if key == nil {
+		return nil, marketdata.ErrNoKey
+	}
+	recordKeyQuota(kp.name, key)
+	value, err := keyCipher.Decrypt(key.Key)
+	if err != nil {
+		logging.FromContext(ctx).Error("decrypting API key failed", logging.Fields{"provider": kp.name, "keyId": key.ID.Hex(), "error": err})
+		return nil, fmt.Errorf("%w: %v", marketdata.ErrNoKey, err)
+	}
+	return &marketdata.APIKey{ID: key.ID.Hex(), Value: value}, nil
+}
+
+func (kp keyPool) Exhausted(ctx context.Context, key *marketdata.APIKey) error {
+	writeCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBWrite)
+	defer cancel()
+
+	logging.FromContext(ctx).Warn("API key out of quota", logging.Fields{"provider": kp.name, "keyId": key.ID})
+	keyQuotaRemaining.WithLabelValues(kp.name, key.ID).Set(0)
+	return repo.MarkKeyExhausted(writeCtx, key.ID)
+}
+
+// providerNameForTicker uses the exchange of an already stored stock to pick
+// the provider from providers.byExchange (e.g. "NasdaqGS=yahoo,NYSE=yahoo"),
+// falling back to providers.default.
+func providerNameForTicker(ctx context.Context, ticker string) string {
+	if conf.Providers.ByExchange != "" {
+		readCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBRead)
+		stock, err := repo.GetStock(readCtx, ticker)
+		cancel()
+		if err != nil {
+			logging.FromContext(ctx).Error("getting exchange failed", logging.Fields{"ticker": ticker, "error": err})
+		} else if stock != nil {
+			if name := exchangeProvider(stock.Exchange); name != "" {
+				return name
+			}
+		}
+	}
+
+	if conf.Providers.Default != "" {
+		return strings.ToLower(conf.Providers.Default)
+	}
+	return fallbackProvider
+}
+
+func exchangeProvider(exchange string) string {
+	for _, pair := range strings.Split(conf.Providers.ByExchange, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), exchange) {
+			return strings.ToLower(strings.TrimSpace(kv[1]))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"stocks/stocksdb"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type StockResponse struct {
+	Success   bool        `json:"status"`
+	Message   string      `json:"message"`
+	Stock     interface{} `json:"stock"`
+	RequestID string      `json:"requestId,omitempty"`
+}
+
+type StockListResponse struct {
+	Success    bool          `json:"status"`
+	Message    string        `json:"message"`
+	Count      int           `json:"count"`
+	Stocks     []interface{} `json:"stocks"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	RequestID  string        `json:"requestId,omitempty"`
+}
+
+const defaultListLimit = 50
+const maxListLimit = 500
+
+func getStock(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(mux.Vars(r)["ticker"])
+
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, err.Error(), ticker)
+		return
+	}
+
+	var body interface{}
+	if archived, _ := strconv.ParseBool(r.URL.Query().Get("archived")); archived {
+		readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+		stock, err := repo.GetArchivedStock(readCtx, ticker)
+		cancel()
+		if err != nil {
+			writeDBError(w, r, "Error getting archived stock "+ticker+".", ticker)
+			return
+		}
+		if stock == nil {
+			writeError(w, r, http.StatusNotFound, errCodeNotFound, "Stock "+ticker+" is not archived.", ticker)
+			return
+		}
+		body = stock
+	} else {
+		readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+		stock, err := repo.GetStock(readCtx, ticker)
+		cancel()
+		if err != nil {
+			writeDBError(w, r, "Error getting stock "+ticker+".", ticker)
+			return
+		}
+		if stock == nil {
+			writeError(w, r, http.StatusNotFound, errCodeNotFound, "Stock "+ticker+" not found.", ticker)
+			return
+		}
+		body = stock
+	}
+
+	if len(fields) > 0 {
+		body, err = projectFields(body, fields)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, errCodeInternal, "Error encoding stock "+ticker+".", ticker)
+			return
+		}
+	}
+
+	writeJSON(w, r, http.StatusOK, &StockResponse{true, "OK", body, requestID(r)})
+}
+
+// parseFields splits a comma separated fields parameter and checks every
+// name against the json names of the Stock fields.
+func parseFields(param string) ([]string, error) {
+	if param == "" {
+		return nil, nil
+	}
+	return validateFields(strings.Split(param, ","))
+}
+
+func validateFields(names []string) ([]string, error) {
+	var fields, unknown []string
+	for _, f := range names {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if _, ok := stocksdb.StockField(f); !ok {
+			unknown = append(unknown, f)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if len(unknown) > 0 {
+		return nil, errors.New("unknown fields: " + strings.Join(unknown, ", "))
+	}
+	return fields, nil
+}
+
+func projectFields(v interface{}, fields []string) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+
+	projected := map[string]json.RawMessage{"ticker": all["ticker"]}
+	for _, f := range fields {
+		projected[f] = all[f]
+	}
+	return projected, nil
+}
+
+func listStocks(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	fields, err := parseFields(params.Get("fields"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, err.Error(), "")
+		return
+	}
+
+	q := stocksdb.StockQuery{
+		Filters: make(map[string]string),
+		Limit:   defaultListLimit,
+		Cursor:  params.Get("cursor"),
+	}
+	for _, f := range stocksdb.StockFilterFields {
+		q.Filters[f] = params.Get(f)
+	}
+
+	sort := strings.ToLower(params.Get("sort"))
+	if strings.HasPrefix(sort, "-") {
+		q.Descending = true
+		sort = sort[1:]
+	}
+	q.SortField = sort
+
+	if l := params.Get("limit"); l != "" {
+		q.Limit, err = strconv.ParseInt(l, 10, 64)
+		if err != nil || q.Limit < 1 || q.Limit > maxListLimit {
+			writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "limit must be between 1 and "+strconv.Itoa(maxListLimit)+".", "")
+			return
+		}
+	}
+
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	stocks, next, err := repo.ListStocks(readCtx, q)
+	cancel()
+	if err == stocksdb.ErrInvalidSort {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Cannot sort on "+sort+".", "")
+		return
+	}
+	if err == stocksdb.ErrInvalidCursor {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid cursor for this query.", "")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Error listing stocks.", "")
+		return
+	}
+
+	resp := StockListResponse{Success: true, Message: "OK", Count: len(stocks), Stocks: make([]interface{}, 0, len(stocks)), NextCursor: next, RequestID: requestID(r)}
+	for i := range stocks {
+		var item interface{} = &stocks[i]
+		if len(fields) > 0 {
+			item, err = projectFields(&stocks[i], fields)
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, errCodeInternal, "Error encoding stock "+stocks[i].Ticker+".", stocks[i].Ticker)
+				return
+			}
+		}
+		resp.Stocks = append(resp.Stocks, item)
+	}
+
+	writeJSON(w, r, http.StatusOK, &resp)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"stocks/stocksdb"
+	"strings"
+)
+
+// dbCredentialsFound is false when the MongoDB user or password secret is
+// missing or empty.
+var dbCredentialsFound bool
+
+type ReadinessCheck struct {
+	Name    string `json:"name"`
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Success   bool             `json:"status"`
+	Message   string           `json:"message"`
+	Checks    []ReadinessCheck `json:"checks"`
+	RequestID string           `json:"requestId,omitempty"`
+}
+
+// livez only tells that the process serves requests.
+func livez(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, &Response{true, "OK", requestID(r)})
+}
+
+// readyz answers 503 unless MongoDB can be used and the default provider
+// has an API key, so no traffic is routed to a pod that cannot import.
+func readyz(w http.ResponseWriter, r *http.Request) {
+	checks := []ReadinessCheck{checkCredentials(), checkMongoDB(r.Context()), checkProviderKey(r.Context())}
+	if conf.Readiness.CheckCompetitors {
+		checks = append(checks, checkCompetitors(r.Context()))
+	}
+
+	resp := ReadinessResponse{Success: true, Message: "Ready", Checks: checks, RequestID: requestID(r)}
+	status := http.StatusOK
+	for _, c := range checks {
+		if !c.Ready {
+			resp.Success = false
+			resp.Message = "Not ready"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(w, r, status, &resp)
+}
+
+func checkCredentials() ReadinessCheck {
+	if !dbCredentialsFound {
+		return ReadinessCheck{"credentials", false, "MongoDB user or password secret is missing"}
+	}
+	return ReadinessCheck{"credentials", true, ""}
+}
+
+func checkMongoDB(ctx context.Context) ReadinessCheck {
+	if repo == nil {
+		return ReadinessCheck{"mongodb", false, "not connected"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBRead)
+	defer cancel()
+	if err := repo.Ping(ctx); err != nil {
+		return ReadinessCheck{"mongodb", false, err.Error()}
+	}
+	return ReadinessCheck{"mongodb", true, ""}
+}
+
+func checkProviderKey(ctx context.Context) ReadinessCheck {
+	name := fallbackProvider
+	if conf.Providers.Default != "" {
+		name = strings.ToLower(conf.Providers.Default)
+	}
+	check := ReadinessCheck{Name: name + "_key"}
+	if entry, ok := providerRegistry[name]; ok && entry.newOffline != nil && entry.newOffline() != nil {
+		check.Ready = true
+		check.Message = "offline"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBRead)
+	defer cancel()
+	key, err := repo.GetKey(ctx, name)
+	switch {
+	case err != nil:
+		check.Message = err.Error()
+	case key == nil:
+		check.Message = "no enabled API key stored"
+	default:
+		check.Ready = true
+	}
+	return check
+}
+
+func checkCompetitors(ctx context.Context) ReadinessCheck {
+	ctx, cancel := context.WithTimeout(ctx, conf.Timeouts.Competitors)
+	defer cancel()
+	if err := stocksdb.PingCompetitors(ctx); err != nil {
+		return ReadinessCheck{"competitors", false, err.Error()}
+	}
+	return ReadinessCheck{"competitors", true, ""}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"stocks/marketdata"
+	"stocks/stocksdb"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var repositoryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "stocks_db_operation_duration_seconds",
+	Help:    "Duration of repository operations by operation and outcome.",
+	Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+}, []string{"operation", "outcome"})
+
+// instrumentedRepository records the duration of every call to next in
+// repositoryDuration, labelled with the method name.
+type instrumentedRepository struct {
+	next stocksdb.StockRepository
+}
+
+var _ stocksdb.StockRepository = instrumentedRepository{}
+
+func observeRepository(operation string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	repositoryDuration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+func (r instrumentedRepository) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := r.next.Ping(ctx)
+	observeRepository("Ping", start, err)
+	return err
+}
+
+func (r instrumentedRepository) FindStock(ctx context.Context, ticker string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.FindStock(ctx, ticker)
+	observeRepository("FindStock", start, err)
+	return ok, err
+}
+
+func (r instrumentedRepository) GetStock(ctx context.Context, ticker string) (*stocksdb.Stock, error) {
+	start := time.Now()
+	stock, err := r.next.GetStock(ctx, ticker)
+	observeRepository("GetStock", start, err)
+	return stock, err
+}
+
+func (r instrumentedRepository) NewStock(ctx context.Context, f *marketdata.Fundamentals) (*stocksdb.Stock, error) {
+	start := time.Now()
+	stock, err := r.next.NewStock(ctx, f)
+	observeRepository("NewStock", start, err)
+	return stock, err
+}
+
+func (r instrumentedRepository) UpdateStock(ctx context.Context, f *marketdata.Fundamentals, ticker string) (*stocksdb.Stock, error) {
+	start := time.Now()
+	stock, err := r.next.UpdateStock(ctx, f, ticker)
+	observeRepository("UpdateStock", start, err)
+	return stock, err
+}
+
+func (r instrumentedRepository) ListStocks(ctx context.Context, q stocksdb.StockQuery) ([]stocksdb.Stock, string, error) {
+	start := time.Now()
+	stocks, next, err := r.next.ListStocks(ctx, q)
+	observeRepository("ListStocks", start, err)
+	return stocks, next, err
+}
+
+func (r instrumentedRepository) ScreenStocks(ctx context.Context, cond stocksdb.Condition, limit int64) ([]stocksdb.Stock, error) {
+	start := time.Now()
+	stocks, err := r.next.ScreenStocks(ctx, cond, limit)
+	observeRepository("ScreenStocks", start, err)
+	return stocks, err
+}
+
+func (r instrumentedRepository) ArchiveStock(ctx context.Context, ticker, reason string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.ArchiveStock(ctx, ticker, reason)
+	observeRepository("ArchiveStock", start, err)
+	return ok, err
+}
+
+func (r instrumentedRepository) RestoreStock(ctx context.Context, ticker string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.RestoreStock(ctx, ticker)
+	observeRepository("RestoreStock", start, err)
+	return ok, err
+}
+
+func (r instrumentedRepository) GetArchivedStock(ctx context.Context, ticker string) (*stocksdb.ArchivedStock, error) {
+	start := time.Now()
+	archived, err := r.next.GetArchivedStock(ctx, ticker)
+	observeRepository("GetArchivedStock", start, err)
+	return archived, err
+}
+
+func (r instrumentedRepository) GetKey(ctx context.Context, name string) (*stocksdb.Key, error) {
+	start := time.Now()
+	key, err := r.next.GetKey(ctx, name)
+	observeRepository("GetKey", start, err)
+	return key, err
+}
+
+func (r instrumentedRepository) AcquireKey(ctx context.Context, name string) (*stocksdb.Key, error) {
+	start := time.Now()
+	key, err := r.next.AcquireKey(ctx, name)
+	observeRepository("AcquireKey", start, err)
+	return key, err
+}
+
+func (r instrumentedRepository) MarkKeyExhausted(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.MarkKeyExhausted(ctx, id)
+	observeRepository("MarkKeyExhausted", start, err)
+	return err
+}
+
+func (r instrumentedRepository) InsertKey(ctx context.Context, key *stocksdb.Key) error {
+	start := time.Now()
+	err := r.next.InsertKey(ctx, key)
+	observeRepository("InsertKey", start, err)
+	return err
+}
+
+func (r instrumentedRepository) ListKeys(ctx context.Context, name string) ([]stocksdb.Key, error) {
+	start := time.Now()
+	keys, err := r.next.ListKeys(ctx, name)
+	observeRepository("ListKeys", start, err)
+	return keys, err
+}
+
+func (r instrumentedRepository) SetKeyDisabled(ctx context.Context, id string, disabled bool) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.SetKeyDisabled(ctx, id, disabled)
+	observeRepository("SetKeyDisabled", start, err)
+	return ok, err
+}
+
+func (r instrumentedRepository) DeleteKey(ctx context.Context, id string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.DeleteKey(ctx, id)
+	observeRepository("DeleteKey", start, err)
+	return ok, err
+}
+
+func (r instrumentedRepository) SaveRawResponse(ctx context.Context, ticker, provider string, fetchedAt time.Time, body []byte) error {
+	start := time.Now()
+	err := r.next.SaveRawResponse(ctx, ticker, provider, fetchedAt, body)
+	observeRepository("SaveRawResponse", start, err)
+	return err
+}
+
+func (r instrumentedRepository) GetRawResponse(ctx context.Context, ticker string, at time.Time) (*stocksdb.RawResponse, error) {
+	start := time.Now()
+	raw, err := r.next.GetRawResponse(ctx, ticker, at)
+	observeRepository("GetRawResponse", start, err)
+	return raw, err
+}
+
+func (r instrumentedRepository) RawResponseTickers(ctx context.Context) ([]string, error) {
+	start := time.Now()
+	tickers, err := r.next.RawResponseTickers(ctx)
+	observeRepository("RawResponseTickers", start, err)
+	return tickers, err
+}
+
+func (r instrumentedRepository) SaveSnapshot(ctx context.Context, stock *stocksdb.Stock) error {
+	start := time.Now()
+	err := r.next.SaveSnapshot(ctx, stock)
+	observeRepository("SaveSnapshot", start, err)
+	return err
+}
+
+func (r instrumentedRepository) GetHistory(ctx context.Context, ticker, field string, from, to time.Time) ([]stocksdb.HistoryPoint, error) {
+	start := time.Now()
+	points, err := r.next.GetHistory(ctx, ticker, field, from, to)
+	observeRepository("GetHistory", start, err)
+	return points, err
+}
+
+func (r instrumentedRepository) NewJob(ctx context.Context, ticker, provider string) (*stocksdb.Job, error) {
+	start := time.Now()
+	job, err := r.next.NewJob(ctx, ticker, provider)
+	observeRepository("NewJob", start, err)
+	return job, err
+}
+
+func (r instrumentedRepository) GetJob(ctx context.Context, id string) (*stocksdb.Job, error) {
+	start := time.Now()
+	job, err := r.next.GetJob(ctx, id)
+	observeRepository("GetJob", start, err)
+	return job, err
+}
+
+func (r instrumentedRepository) ClaimJob(ctx context.Context) (*stocksdb.Job, error) {
+	start := time.Now()
+	job, err := r.next.ClaimJob(ctx)
+	observeRepository("ClaimJob", start, err)
+	return job, err
+}
+
+func (r instrumentedRepository) FinishJob(ctx context.Context, id primitive.ObjectID, state, result, message, jobErr string) error {
+	start := time.Now()
+	err := r.next.FinishJob(ctx, id, state, result, message, jobErr)
+	observeRepository("FinishJob", start, err)
+	return err
+}
+
+func (r instrumentedRepository) RetryJob(ctx context.Context, id primitive.ObjectID, jobErr string, runAfter time.Time) error {
+	start := time.Now()
+	err := r.next.RetryJob(ctx, id, jobErr, runAfter)
+	observeRepository("RetryJob", start, err)
+	return err
+}
+
+func (r instrumentedRepository) ReleaseJob(ctx context.Context, id primitive.ObjectID) error {
+	start := time.Now()
+	err := r.next.ReleaseJob(ctx, id)
+	observeRepository("ReleaseJob", start, err)
+	return err
+}
+
+func (r instrumentedRepository) RequeueStaleJobs(ctx context.Context, maxAge time.Duration) (int64, error) {
+	start := time.Now()
+	n, err := r.next.RequeueStaleJobs(ctx, maxAge)
+	observeRepository("RequeueStaleJobs", start, err)
+	return n, err
+}
+
+func (r instrumentedRepository) SaveScreen(ctx context.Context, screen *stocksdb.Screen) error {
+	start := time.Now()
+	err := r.next.SaveScreen(ctx, screen)
+	observeRepository("SaveScreen", start, err)
+	return err
+}
+
+func (r instrumentedRepository) GetScreen(ctx context.Context, name string) (*stocksdb.Screen, error) {
+	start := time.Now()
+	screen, err := r.next.GetScreen(ctx, name)
+	observeRepository("GetScreen", start, err)
+	return screen, err
+}
+
+func (r instrumentedRepository) ListScreens(ctx context.Context) ([]stocksdb.Screen, error) {
+	start := time.Now()
+	screens, err := r.next.ListScreens(ctx)
+	observeRepository("ListScreens", start, err)
+	return screens, err
+}
+
+func (r instrumentedRepository) DeleteScreen(ctx context.Context, name string) (bool, error) {
+	start := time.Now()
+	ok, err := r.next.DeleteScreen(ctx, name)
+	observeRepository("DeleteScreen", start, err)
+	return ok, err
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"stocks/stocksdb"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func operationCount(t *testing.T, operation, outcome string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := repositoryDuration.WithLabelValues(operation, outcome).(prometheus.Histogram).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestInstrumentedRepositoryLabelsOperations(t *testing.T) {
+	r := instrumentedRepository{stocksdb.NewMemoryRepository()}
+	findBefore := operationCount(t, "FindStock", "ok")
+	listBefore := operationCount(t, "ListStocks", "error")
+
+	if _, err := r.FindStock(context.Background(), "AAPL"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.ListStocks(context.Background(), stocksdb.StockQuery{SortField: "nosuchfield"}); err == nil {
+		t.Fatal("listing by an unknown field succeeded")
+	}
+
+	if n := operationCount(t, "FindStock", "ok") - findBefore; n != 1 {
+		t.Errorf("FindStock observed %d times, want 1", n)
+	}
+	if n := operationCount(t, "ListStocks", "error") - listBefore; n != 1 {
+		t.Errorf("failed ListStocks observed %d times, want 1", n)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"stocks/marketdata"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// reprocessStock rebuilds the stock document from the newest stored provider
+// response, or the newest one fetched at or before ?at=, without calling the
+// provider. Only stocks in the stocks collection are rebuilt; archived and
+// never imported tickers are answered with 404.
+func reprocessStock(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(mux.Vars(r)["ticker"])
+
+	at, dateOnly, err := parseTimeParam(r.URL.Query().Get("at"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid at date.", ticker)
+		return
+	}
+	if dateOnly {
+		at = at.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	res := reprocessTicker(r.Context(), ticker, at)
+	switch {
+	case res.Status == importNotFound:
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, res.Message, ticker)
+	case res.Status == importUnavailable:
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, res.Message, ticker)
+	case !res.imported():
+		writeError(w, r, http.StatusInternalServerError, errCodeInternal, res.Message, ticker)
+	default:
+		writeJSON(w, r, http.StatusOK, &Response{true, res.Message, requestID(r)})
+	}
+}
+
+// reprocessStocks takes the same body as importStocks. An empty body
+// reprocesses every stored stock with a stored response.
+func reprocessStocks(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Error reading request body.", "")
+		return
+	}
+	tickers, err := parseTickers(body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Error parsing tickers: "+err.Error(), "")
+		return
+	}
+
+	if len(tickers) == 0 {
+		tickers, err = storedResponseTickers(r.Context())
+		if err != nil {
+			writeDBError(w, r, "Error listing stored responses.", "")
+			return
+		}
+	}
+
+	results := importTickers(tickers, func(ticker string) importResult {
+		return reprocessTicker(r.Context(), ticker, time.Time{})
+	})
+
+	writeBulkResults(w, r, "Reprocessed", results)
+}
+
+// storedResponseTickers returns the tickers with a stored response that are
+// in the stocks collection, leaving out archived ones.
+func storedResponseTickers(ctx context.Context) ([]string, error) {
+	readCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBRead)
+	all, err := repo.RawResponseTickers(readCtx)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make([]string, 0, len(all))
+	for _, ticker := range all {
+		readCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBRead)
+		exists, err := repo.FindStock(readCtx, ticker)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			tickers = append(tickers, ticker)
+		}
+	}
+	return tickers, nil
+}
+
+func reprocessTicker(ctx context.Context, ticker string, at time.Time) importResult {
+	readCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBRead)
+	exists, err := repo.FindStock(readCtx, ticker)
+	cancel()
+	if err != nil {
+		return importResult{Ticker: ticker, Status: importUnavailable, Message: "Error loading stock " + ticker + ". Check the database."}
+	}
+	if !exists {
+		return importResult{Ticker: ticker, Status: importNotFound, Message: "Stock " + ticker + " is not stored. Only stored stocks are reprocessed."}
+	}
+
+	readCtx, cancel = context.WithTimeout(ctx, conf.Timeouts.DBRead)
+	raw, err := repo.GetRawResponse(readCtx, ticker, at)
+	cancel()
+	if err != nil {
+		return importResult{Ticker: ticker, Status: importUnavailable, Message: "Error loading stored response for " + ticker + ". Check the database."}
+	}
+	if raw == nil {
+		return importResult{Ticker: ticker, Status: importNotFound, Message: "No stored response for " + ticker + "."}
+	}
+
+	entry, ok := providerRegistry[raw.Provider]
+	if !ok {
+		return importResult{Ticker: ticker, Status: importProviderError, Message: "Unknown provider " + raw.Provider + " of stored response for " + ticker + "."}
+	}
+	f, err := entry.parse(raw.Body)
+	if errors.Is(err, marketdata.ErrNotFound) {
+		return importResult{Ticker: ticker, Status: importNotFound, Message: "Stored response for " + ticker + " has no stock data."}
+	}
+	if err != nil {
+		return importResult{Ticker: ticker, Status: importProviderError, Message: "Error parsing stored response for " + ticker + "."}
+	}
+
+	res, _ := storeStock(ctx, f, ticker)
+	return res
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stocks/stocksdb"
+	"testing"
+	"time"
+)
+
+func TestReprocessSkipsArchivedStocks(t *testing.T) {
+	mem := setupImport(t, nil)
+	ctx := context.Background()
+	for _, ticker := range []string{"AAPL", "MSFT"} {
+		if _, err := mem.NewStock(ctx, fakeFundamentals(ticker)); err != nil {
+			t.Fatal(err)
+		}
+		if err := mem.SaveRawResponse(ctx, ticker, "fake", time.Now(), fakeFundamentals(ticker).Raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := mem.ArchiveStock(ctx, "AAPL", "delisted"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/reprocess", nil))
+	var reply BulkResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	if rec.Code != http.StatusOK || len(reply.Results) != 1 || reply.Results[0].Ticker != "MSFT" || reply.Results[0].Status != importUpdated {
+		t.Fatalf("got %d %s, want only MSFT updated", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/reprocess/AAPL", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("reprocessing archived AAPL answered %d %s, want 404", rec.Code, rec.Body.String())
+	}
+
+	if found, _ := mem.FindStock(ctx, "AAPL"); found {
+		t.Error("archived AAPL was put back into stocks")
+	}
+}
+
+// failingRepository fails every raw response read like an unreachable
+// database.
+type failingRepository struct {
+	*stocksdb.MemoryRepository
+}
+
+func (failingRepository) GetRawResponse(ctx context.Context, ticker string, at time.Time) (*stocksdb.RawResponse, error) {
+	return nil, errors.New("server selection timeout")
+}
+
+func TestReprocessDatabaseErrorIsUnavailable(t *testing.T) {
+	mem := setupImport(t, nil)
+	if _, err := mem.NewStock(context.Background(), fakeFundamentals("AAPL")); err != nil {
+		t.Fatal(err)
+	}
+	repo = failingRepository{mem}
+
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/reprocess/AAPL", nil))
+	var reply ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || reply.Code != errCodeUnavailable {
+		t.Errorf("got %d %s, want 503 %s", rec.Code, rec.Body.String(), errCodeUnavailable)
+	}
+}
@@ -0,0 +1,307 @@
+package screener
+
+import (
+	"errors"
+	"fmt"
+	"stocks/stocksdb"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Grammar:
+//
+//	expr       = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" expr ")" | comparison
+//	comparison = field op value
+//	op         = "=" | "!=" | "<" | "<=" | ">" | ">="
+//	value      = number | string
+//
+// Fields are the json names of the top level stocksdb.Stock fields.
+
+type Query struct {
+	cond   stocksdb.Condition
+	fields []string
+}
+
+// Condition returns the parsed expression for StockRepository.ScreenStocks.
+func (q *Query) Condition() stocksdb.Condition {
+	return q.cond
+}
+
+// Fields returns the stock fields referenced by the expression in order of
+// first appearance.
+func (q *Query) Fields() []string {
+	return q.fields
+}
+
+func Parse(expr string) (*Query, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, errors.New("empty expression")
+	}
+
+	p := &parser{tokens: tokens, seen: make(map[string]bool)}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+
+	return &Query{cond, p.fields}, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	r := []rune(s)
+
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '=' || c == '<' || c == '>' || c == '!':
+			start := i
+			i++
+			if i < len(r) && r[i] == '=' {
+				i++
+			}
+			op := string(r[start:i])
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected '!' at position %d", start)
+			}
+			tokens = append(tokens, token{tokOp, op, start})
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			var sb strings.Builder
+			for ; i < len(r) && r[i] != c; i++ {
+				if r[i] == '\\' && i+1 < len(r) {
+					i++
+				}
+				sb.WriteRune(r[i])
+			}
+			if i >= len(r) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, token{tokString, sb.String(), start})
+		case unicode.IsDigit(c) || c == '-' || c == '+' || c == '.':
+			start := i
+			i++
+			for i < len(r) && (unicode.IsDigit(r[i]) || r[i] == '.' || r[i] == 'e' || r[i] == 'E' ||
+				((r[i] == '-' || r[i] == '+') && (r[i-1] == 'e' || r[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(r[start:i]), start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(r) && (unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]) || r[i] == '_') {
+				i++
+			}
+			word := string(r[start:i])
+			switch strings.ToUpper(word) {
+			case "AND":
+				tokens = append(tokens, token{tokAnd, word, start})
+			case "OR":
+				tokens = append(tokens, token{tokOr, word, start})
+			case "NOT":
+				tokens = append(tokens, token{tokNot, word, start})
+			default:
+				tokens = append(tokens, token{tokIdent, word, start})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", c, i)
+		}
+	}
+
+	return append(tokens, token{tokEOF, "end of expression", len(r)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	fields []string
+	seen   map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (stocksdb.Condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := stocksdb.Or{left}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, right)
+	}
+	if len(terms) == 1 {
+		return left, nil
+	}
+	return terms, nil
+}
+
+func (p *parser) parseAnd() (stocksdb.Condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	terms := stocksdb.And{left}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, right)
+	}
+	if len(terms) == 1 {
+		return left, nil
+	}
+	return terms, nil
+}
+
+func (p *parser) parseUnary() (stocksdb.Condition, error) {
+	switch t := p.peek(); t.kind {
+	case tokNot:
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return stocksdb.Not{Condition: inner}, nil
+	case tokLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')' at position %d, got %q", closing.pos, closing.text)
+		}
+		return inner, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+var compareOps = map[string]stocksdb.CompareOp{
+	"=":  stocksdb.OpEq,
+	"!=": stocksdb.OpNe,
+	"<":  stocksdb.OpLt,
+	"<=": stocksdb.OpLte,
+	">":  stocksdb.OpGt,
+	">=": stocksdb.OpGte,
+}
+
+func (p *parser) parseComparison() (stocksdb.Condition, error) {
+	ft := p.next()
+	if ft.kind != tokIdent {
+		return nil, fmt.Errorf("expected field name at position %d, got %q", ft.pos, ft.text)
+	}
+	field := strings.ToLower(ft.text)
+	kind, ok := stocksdb.StockField(field)
+	if !ok || field == "id" {
+		return nil, fmt.Errorf("unknown field %q at position %d", ft.text, ft.pos)
+	}
+
+	ot := p.next()
+	op, ok := compareOps[ot.text]
+	if ot.kind != tokOp || !ok {
+		return nil, fmt.Errorf("expected operator after %s at position %d, got %q", ft.text, ot.pos, ot.text)
+	}
+
+	vt := p.next()
+	var value interface{}
+	switch kind {
+	case stocksdb.FieldNumber:
+		if vt.kind != tokNumber {
+			return nil, fmt.Errorf("field %s needs a number at position %d, got %q", field, vt.pos, vt.text)
+		}
+		f, err := strconv.ParseFloat(vt.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", vt.text, vt.pos)
+		}
+		value = f
+	case stocksdb.FieldString:
+		if vt.kind != tokString {
+			return nil, fmt.Errorf("field %s needs a quoted string at position %d, got %q", field, vt.pos, vt.text)
+		}
+		value = vt.text
+	case stocksdb.FieldTime:
+		if vt.kind != tokString {
+			return nil, fmt.Errorf("field %s needs a quoted date at position %d, got %q", field, vt.pos, vt.text)
+		}
+		t, err := parseTime(vt.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q at position %d", vt.text, vt.pos)
+		}
+		value = t
+	default:
+		return nil, fmt.Errorf("field %s cannot be used in a screen", field)
+	}
+
+	if !p.seen[field] {
+		p.seen[field] = true
+		p.fields = append(p.fields, field)
+	}
+
+	return stocksdb.Comparison{Field: field, Op: op, Value: value}, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
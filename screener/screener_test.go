@@ -0,0 +1,122 @@
+package screener
+
+import (
+	"reflect"
+	"stocks/stocksdb"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cmp(field string, op stocksdb.CompareOp, value interface{}) stocksdb.Comparison {
+	return stocksdb.Comparison{Field: field, Op: op, Value: value}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr   string
+		want   stocksdb.Condition
+		fields []string
+	}{
+		{"beta < 1", cmp("beta", stocksdb.OpLt, 1.0), []string{"beta"}},
+		{"beta<=1", cmp("beta", stocksdb.OpLte, 1.0), []string{"beta"}},
+		{"Beta > -1.5", cmp("beta", stocksdb.OpGt, -1.5), []string{"beta"}},
+		{"beta >= .5", cmp("beta", stocksdb.OpGte, 0.5), []string{"beta"}},
+		{"beta != +2", cmp("beta", stocksdb.OpNe, 2.0), []string{"beta"}},
+		{"employeeno = 1e3", cmp("employeeno", stocksdb.OpEq, 1000.0), []string{"employeeno"}},
+		{"beta < 2.5E-1", cmp("beta", stocksdb.OpLt, 0.25), []string{"beta"}},
+		{`sector = "Technology"`, cmp("sector", stocksdb.OpEq, "Technology"), []string{"sector"}},
+		{`name = 'O\'Reilly'`, cmp("name", stocksdb.OpEq, "O'Reilly"), []string{"name"}},
+		{`name = "say \"hi\""`, cmp("name", stocksdb.OpEq, `say "hi"`), []string{"name"}},
+		{`name = 'AND OR ( )'`, cmp("name", stocksdb.OpEq, "AND OR ( )"), []string{"name"}},
+		{`name = ""`, cmp("name", stocksdb.OpEq, ""), []string{"name"}},
+		{"lastupdated > '2024-01-31'", cmp("lastupdated", stocksdb.OpGt, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)), []string{"lastupdated"}},
+		{"lastupdated > '2024-01-31T10:00:00Z'", cmp("lastupdated", stocksdb.OpGt, time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)), []string{"lastupdated"}},
+
+		// AND binds tighter than OR.
+		{"beta < 1 OR roic > 0.1 AND sector = 'Energy'", stocksdb.Or{
+			cmp("beta", stocksdb.OpLt, 1.0),
+			stocksdb.And{cmp("roic", stocksdb.OpGt, 0.1), cmp("sector", stocksdb.OpEq, "Energy")},
+		}, []string{"beta", "roic", "sector"}},
+		{"beta < 1 AND roic > 0.1 OR sector = 'Energy'", stocksdb.Or{
+			stocksdb.And{cmp("beta", stocksdb.OpLt, 1.0), cmp("roic", stocksdb.OpGt, 0.1)},
+			cmp("sector", stocksdb.OpEq, "Energy"),
+		}, []string{"beta", "roic", "sector"}},
+		{"beta < 1 and roic > 0 and pegratio < 2", stocksdb.And{
+			cmp("beta", stocksdb.OpLt, 1.0), cmp("roic", stocksdb.OpGt, 0.0), cmp("pegratio", stocksdb.OpLt, 2.0),
+		}, []string{"beta", "roic", "pegratio"}},
+		{"(beta < 1 OR roic > 0.1) AND sector = 'Energy'", stocksdb.And{
+			stocksdb.Or{cmp("beta", stocksdb.OpLt, 1.0), cmp("roic", stocksdb.OpGt, 0.1)},
+			cmp("sector", stocksdb.OpEq, "Energy"),
+		}, []string{"beta", "roic", "sector"}},
+		{"((beta < 1))", cmp("beta", stocksdb.OpLt, 1.0), []string{"beta"}},
+
+		// NOT binds tighter than AND and becomes a Not, which Store turns
+		// into $nor.
+		{"NOT beta < 1 AND roic > 0", stocksdb.And{
+			stocksdb.Not{Condition: cmp("beta", stocksdb.OpLt, 1.0)},
+			cmp("roic", stocksdb.OpGt, 0.0),
+		}, []string{"beta", "roic"}},
+		{"not (beta < 1 or beta > 2)", stocksdb.Not{Condition: stocksdb.Or{
+			cmp("beta", stocksdb.OpLt, 1.0), cmp("beta", stocksdb.OpGt, 2.0),
+		}}, []string{"beta"}},
+		{"NOT NOT beta < 1", stocksdb.Not{Condition: stocksdb.Not{Condition: cmp("beta", stocksdb.OpLt, 1.0)}}, []string{"beta"}},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.Condition(), tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.expr, q.Condition(), tt.want)
+		}
+		if !reflect.DeepEqual(q.Fields(), tt.fields) {
+			t.Errorf("Parse(%q) fields = %v, want %v", tt.expr, q.Fields(), tt.fields)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"", "empty expression"},
+		{"   ", "empty expression"},
+		{"beta", `expected operator after beta at position 4, got "end of expression"`},
+		{"beta <", `field beta needs a number at position 6, got "end of expression"`},
+		{"beta < 'one'", `field beta needs a number at position 7, got "one"`},
+		{"beta < 1.2.3", `invalid number "1.2.3" at position 7`},
+		{"beta < -", `invalid number "-" at position 7`},
+		{"sector = Energy", `field sector needs a quoted string at position 9, got "Energy"`},
+		{"sector = 5", `field sector needs a quoted string at position 9, got "5"`},
+		{"lastupdated > 5", `field lastupdated needs a quoted date at position 14, got "5"`},
+		{"lastupdated > 'yesterday'", `invalid date "yesterday" at position 14`},
+		{"nosuchfield = 1", `unknown field "nosuchfield" at position 0`},
+		{"id = 'x'", `unknown field "id" at position 0`},
+		{"recommtrend = 1", "field recommtrend cannot be used in a screen"},
+		{"1 = beta", `expected field name at position 0, got "1"`},
+		{"beta == 1", `expected operator after beta at position 5, got "=="`},
+		{"beta ! 1", "unexpected '!' at position 5"},
+		{"beta < 1 # 2", "unexpected '#' at position 9"},
+		{"sector = 'Energy", "unterminated string at position 9"},
+		{"(beta < 1", `expected ')' at position 9, got "end of expression"`},
+		{"beta < 1)", `unexpected ")" at position 8`},
+		{"beta < 1 AND", `expected field name at position 12, got "end of expression"`},
+		{"beta < 1 OR OR roic > 0", `expected field name at position 12, got "OR"`},
+		{"beta < 1 roic > 0", `unexpected "roic" at position 9`},
+		{"NOT", `expected field name at position 3, got "end of expression"`},
+		{"()", `expected field name at position 1, got ")"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want error %q", tt.expr, tt.err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) = %q, want %q", tt.expr, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"stocks/screener"
+	"stocks/stocksdb"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ScreenRequest struct {
+	Expression string   `json:"expression"`
+	Columns    []string `json:"columns"`
+	Limit      int64    `json:"limit"`
+}
+
+type ScreenResponse struct {
+	Success   bool                         `json:"status"`
+	Message   string                       `json:"message"`
+	Count     int                          `json:"count"`
+	Results   []map[string]json.RawMessage `json:"results"`
+	RequestID string                       `json:"requestId,omitempty"`
+}
+
+type ScreenDefinitionResponse struct {
+	Success   bool             `json:"status"`
+	Message   string           `json:"message"`
+	Screen    *stocksdb.Screen `json:"screen"`
+	RequestID string           `json:"requestId,omitempty"`
+}
+
+type ScreenListResponse struct {
+	Success   bool              `json:"status"`
+	Message   string            `json:"message"`
+	Screens   []stocksdb.Screen `json:"screens"`
+	RequestID string            `json:"requestId,omitempty"`
+}
+
+const defaultScreenLimit = 100
+const maxScreenLimit = 1000
+
+func runAdhocScreen(w http.ResponseWriter, r *http.Request) {
+	var req ScreenRequest
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes))
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Error reading screen request.", "")
+		return
+	}
+
+	runScreen(w, r, req.Expression, req.Columns, req.Limit)
+}
+
+func runSavedScreen(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var limit int64
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid limit.", "")
+			return
+		}
+	}
+
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	screen, err := repo.GetScreen(readCtx, name)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error getting screen "+name+".", "")
+		return
+	}
+	if screen == nil {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Screen "+name+" not found.", "")
+		return
+	}
+
+	runScreen(w, r, screen.Expression, screen.Columns, limit)
+}
+
+func runScreen(w http.ResponseWriter, r *http.Request, expression string, columns []string, limit int64) {
+	q, err := screener.Parse(expression)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid screen expression: "+err.Error(), "")
+		return
+	}
+	fields, err := validateFields(columns)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, err.Error(), "")
+		return
+	}
+	if len(fields) == 0 {
+		fields = append([]string{"name"}, q.Fields()...)
+	}
+
+	if limit == 0 {
+		limit = defaultScreenLimit
+	}
+	if limit < 1 || limit > maxScreenLimit {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "limit must be between 1 and "+strconv.Itoa(maxScreenLimit)+".", "")
+		return
+	}
+
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	stocks, err := repo.ScreenStocks(readCtx, q.Condition(), limit)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error running screen.", "")
+		return
+	}
+
+	resp := ScreenResponse{Success: true, Message: "OK", Count: len(stocks), Results: make([]map[string]json.RawMessage, 0, len(stocks)), RequestID: requestID(r)}
+	for i := range stocks {
+		row, err := projectFields(&stocks[i], fields)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, errCodeInternal, "Error encoding stock "+stocks[i].Ticker+".", stocks[i].Ticker)
+			return
+		}
+		resp.Results = append(resp.Results, row)
+	}
+
+	writeJSON(w, r, http.StatusOK, &resp)
+}
+
+func saveScreen(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var req ScreenRequest
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBulkBodyBytes))
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Error reading screen definition.", "")
+		return
+	}
+	if _, err := screener.Parse(req.Expression); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid screen expression: "+err.Error(), "")
+		return
+	}
+	columns, err := validateFields(req.Columns)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, err.Error(), "")
+		return
+	}
+
+	screen := &stocksdb.Screen{Name: name, Expression: req.Expression, Columns: columns}
+	writeCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBWrite)
+	err = repo.SaveScreen(writeCtx, screen)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error saving screen "+name+".", "")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &Response{true, "Screen " + name + " saved", requestID(r)})
+}
+
+func getScreen(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	screen, err := repo.GetScreen(readCtx, name)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error getting screen "+name+".", "")
+		return
+	}
+	if screen == nil {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Screen "+name+" not found.", "")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &ScreenDefinitionResponse{Success: true, Message: "OK", Screen: screen, RequestID: requestID(r)})
+}
+
+func listScreens(w http.ResponseWriter, r *http.Request) {
+	readCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBRead)
+	screens, err := repo.ListScreens(readCtx)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error listing screens.", "")
+		return
+	}
+
+	resp := ScreenListResponse{Success: true, Message: "OK", Screens: make([]stocksdb.Screen, 0, len(screens)), RequestID: requestID(r)}
+	resp.Screens = append(resp.Screens, screens...)
+	writeJSON(w, r, http.StatusOK, &resp)
+}
+
+func deleteScreen(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	writeCtx, cancel := context.WithTimeout(r.Context(), conf.Timeouts.DBWrite)
+	deleted, err := repo.DeleteScreen(writeCtx, name)
+	cancel()
+	if err != nil {
+		writeDBError(w, r, "Error deleting screen "+name+".", "")
+		return
+	}
+	if !deleted {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Screen "+name+" not found.", "")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, &Response{true, "Screen " + name + " deleted", requestID(r)})
+}
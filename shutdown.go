@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"stocks/logging"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const closeTimeout = 10 * time.Second
+
+// inFlightImports counts the running imports, from provider selection to
+// the last database write, of requests and jobs alike.
+var inFlightImports int64
+
+// serveUntilSignal serves until SIGTERM or SIGINT, then stops accepting
+// requests and claiming jobs and waits up to the grace period for in-flight
+// requests and running jobs. It returns an error only when the server could
+// not run.
+func serveUntilSignal(s *http.Server) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	var sig os.Signal
+	select {
+	case err := <-serveErr:
+		return err
+	case sig = <-signals:
+	}
+
+	start := time.Now()
+	logger := logging.Default()
+	logger.Info("shutting down", logging.Fields{
+		"signal":          sig.String(),
+		"gracePeriodMs":   conf.Timeouts.ShutdownGrace.Milliseconds(),
+		"importsInFlight": atomic.LoadInt64(&inFlightImports),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.Timeouts.ShutdownGrace)
+	defer cancel()
+	jobsStopped := make(chan error, 1)
+	go func() {
+		jobsStopped <- stopJobWorkers(ctx)
+	}()
+	requestsErr := s.Shutdown(ctx)
+	jobsErr := <-jobsStopped
+
+	fields := logging.Fields{
+		"durationMs":        time.Since(start).Milliseconds(),
+		"requestsDrained":   requestsErr == nil,
+		"jobsDrained":       jobsErr == nil,
+		"importsUnfinished": atomic.LoadInt64(&inFlightImports),
+	}
+	if requestsErr != nil || jobsErr != nil {
+		logger.Warn("shutdown grace period exceeded", fields)
+	} else {
+		logger.Info("shutdown complete", fields)
+	}
+	return nil
+}
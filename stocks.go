@@ -1,49 +1,198 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"stocks/breaker"
+	"stocks/config"
+	"stocks/logging"
+	"stocks/marketdata"
 	"stocks/stocksdb"
 	"stocks/yahoodata"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Response struct {
-	Success bool   `json:"status"`
+	Success   bool   `json:"status"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// ImportResponse reports how many requests the provider needed.
+type ImportResponse struct {
+	Success   bool   `json:"status"`
+	Message   string `json:"message"`
+	Attempts  int    `json:"attempts"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+const (
+	importInserted      = "inserted"
+	importUpdated       = "updated"
+	importNotFound      = "not_found"
+	importProviderError = "provider_error"
+	importUnavailable   = "unavailable"
+)
+
+type importResult struct {
+	Ticker  string `json:"ticker"`
+	Status  string `json:"result"`
 	Message string `json:"message"`
+	// Attempts is the number of provider requests, 0 when nothing was
+	// fetched.
+	Attempts int `json:"attempts,omitempty"`
 }
 
-var mongoDBServerName = os.Getenv("MONGODBSERVERNAME")
-var mongoDBServerPort = os.Getenv("MONGODBSERVERPORT")
-var PORT = os.Getenv("PORT")
+func (r importResult) imported() bool {
+	return r.Status == importInserted || r.Status == importUpdated
+}
+
+// conf is loaded once by main; until then it holds the defaults.
+var conf = config.Default()
+
+// repo is the storage of the handlers and job workers. main sets it to
+// the instrumented MongoDB store; tests can use a stocksdb.MemoryRepository
+// instead.
+var repo stocksdb.StockRepository
 
 func main() {
+	c, printOnly, err := config.Load(os.Args[1:], providerNames())
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logging.Default().Error("loading configuration failed", logging.Fields{"error": err})
+		os.Exit(2)
+	}
+	conf = c
+	if printOnly {
+		if err := conf.Print(os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	logging.SetLevel(logging.ParseLevel(conf.LogLevel))
+
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		logging.Default().Error("setting up tracing failed", logging.Fields{"error": err})
+		os.Exit(1)
+	}
+
+	mongoDBAdminUser, mongoDBAdminUserPassword := getDBCredentials()
+	dbCredentialsFound = mongoDBAdminUser != "" && mongoDBAdminUserPassword != ""
+	if !dbCredentialsFound {
+		logging.Default().Warn("MongoDB credentials missing", logging.Fields{"userFile": conf.MongoDB.UserFile, "passwordFile": conf.MongoDB.PasswordFile})
+	}
+
+	store, err := stocksdb.NewStore(context.Background(), conf.MongoDB.Server, conf.MongoDB.Port, mongoDBAdminUser, mongoDBAdminUserPassword, conf.MongoDB.PoolSize, mongoMetricsMonitor(), otelmongo.NewMonitor())
+	if err != nil {
+		logging.Default().Error("connecting to MongoDB failed", logging.Fields{"error": err})
+		os.Exit(1)
+	}
+	repo = instrumentedRepository{store}
 
-	handleRequests()
+	if conf.Providers.YahooFixturesDir != "" {
+		logging.Default().Info("reading Yahoo data from fixtures", logging.Fields{"dir": conf.Providers.YahooFixturesDir})
+	}
+	yahoodata.DefaultRetryPolicy.MaxAttempts = conf.Providers.YahooMaxAttempts
+	stocksdb.CompetitorsAddress = conf.Competitors.Name + ":" + conf.Competitors.Port
+	setupBreakers()
+	yahoodata.Observe = observeYahooRequest
+	loadAdminSecrets()
+	startJobWorkers()
+	serveErr := serveUntilSignal(newServer())
+	if serveErr != nil {
+		logging.Default().Error("serving HTTP failed", logging.Fields{"error": serveErr})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	if err := store.Close(ctx); err != nil {
+		logging.Default().Error("closing MongoDB client failed", logging.Fields{"error": err})
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logging.Default().Error("flushing traces failed", logging.Fields{"error": err})
+	}
+	cancel()
+
+	if serveErr != nil {
+		os.Exit(1)
+	}
 }
 
-func handleRequests() {
-	myRouter := mux.NewRouter().StrictSlash(true)
-	myRouter.HandleFunc("/v1/import/{ticker}", importStock)
-	myRouter.HandleFunc("/health", health)
-	s := &http.Server{
-		Addr:           ":" + PORT,
-		Handler:        myRouter,
+func newServer() *http.Server {
+	return &http.Server{
+		Addr:           ":" + conf.Port,
+		Handler:        newRouter(),
 		ReadTimeout:    1 * time.Minute,
 		WriteTimeout:   1 * time.Minute,
 		MaxHeaderBytes: 0,
 	}
-	s.ListenAndServe()
+}
+
+func newRouter() http.Handler {
+	myRouter := mux.NewRouter().StrictSlash(true)
+	myRouter.Use(otelmux.Middleware(serviceName))
+	myRouter.HandleFunc("/v1/import", importStocks).Methods("POST")
+	myRouter.HandleFunc("/v1/import/{ticker}", importStock)
+	myRouter.HandleFunc("/v1/reprocess", reprocessStocks).Methods("POST")
+	myRouter.HandleFunc("/v1/reprocess/{ticker}", reprocessStock).Methods("POST")
+	myRouter.HandleFunc("/v1/jobs/{id}", getJob).Methods("GET")
+	myRouter.HandleFunc("/v1/stocks", listStocks).Methods("GET")
+	myRouter.HandleFunc("/v1/stocks/{ticker}", getStock).Methods("GET")
+	myRouter.HandleFunc("/v1/stocks/{ticker}", deleteStock).Methods("DELETE")
+	myRouter.HandleFunc("/v1/stocks/{ticker}/restore", restoreStock).Methods("POST")
+	myRouter.HandleFunc("/v1/stocks/{ticker}/history", getStockHistory).Methods("GET")
+	myRouter.HandleFunc("/v1/screen", runAdhocScreen).Methods("POST")
+	myRouter.HandleFunc("/v1/screens", listScreens).Methods("GET")
+	myRouter.HandleFunc("/v1/screens/{name}", getScreen).Methods("GET")
+	myRouter.HandleFunc("/v1/screens/{name}", saveScreen).Methods("PUT")
+	myRouter.HandleFunc("/v1/screens/{name}", deleteScreen).Methods("DELETE")
+	myRouter.HandleFunc("/v1/screens/{name}/run", runSavedScreen).Methods("GET")
+	myRouter.HandleFunc("/v1/admin/keys", requireAdmin(listKeys)).Methods("GET")
+	myRouter.HandleFunc("/v1/admin/keys", requireAdmin(addKey)).Methods("POST")
+	myRouter.HandleFunc("/v1/admin/keys/{id}/disable", requireAdmin(disableKey)).Methods("POST")
+	myRouter.HandleFunc("/v1/admin/keys/{id}/enable", requireAdmin(enableKey)).Methods("POST")
+	myRouter.HandleFunc("/v1/admin/keys/{id}", requireAdmin(deleteKey)).Methods("DELETE")
+	myRouter.HandleFunc("/health", health)
+	myRouter.Handle("/metrics", metricsHandler())
+	myRouter.HandleFunc("/livez", livez)
+	myRouter.HandleFunc("/readyz", readyz)
+	myRouter.NotFoundHandler = http.HandlerFunc(notFound)
+	myRouter.MethodNotAllowedHandler = http.HandlerFunc(methodNotAllowed)
+	return withMetrics(withRequestID(withAccessLog(myRouter)))
+}
+
+type HealthResponse struct {
+	Success   bool             `json:"status"`
+	Message   string           `json:"message"`
+	Breakers  []breaker.Status `json:"breakers"`
+	RequestID string           `json:"requestId,omitempty"`
 }
 
 func health(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(&Response{true, "OK"})
+	resp := HealthResponse{Success: true, Message: "OK", Breakers: breakerStatuses(), RequestID: requestID(r)}
+	for _, b := range resp.Breakers {
+		if b.State != breaker.Closed.String() {
+			resp.Message = "Degraded"
+		}
+	}
+
+	writeJSON(w, r, http.StatusOK, &resp)
 }
 
 func importStock(w http.ResponseWriter, r *http.Request) {
@@ -52,55 +201,172 @@ func importStock(w http.ResponseWriter, r *http.Request) {
 	key := vars["ticker"]
 	key = strings.ToUpper(key)
 
-	var d *yahoodata.YahooData
-	var ykey *stocksdb.Key
+	provider := r.URL.Query().Get("provider")
+	providers, err := newProviderSet(provider)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Unknown provider "+provider+".", key)
+		return
+	}
 
-	ret := ""
+	if isAsync(r) {
+		job, err := enqueueImport(r.Context(), key, providers.requested)
+		if err != nil {
+			writeDBError(w, r, "Error queueing import of "+key+".", key)
+			return
+		}
+		writeJSON(w, r, http.StatusAccepted, &JobResponse{true, "Import of " + key + " queued", job, requestID(r)})
+		return
+	}
 
-	mongoDBAdminUser, mongoDBAdminUserPassword := getDBCredentials()
+	res := importTicker(r.Context(), providers, key)
+	if !res.imported() {
+		writeImportError(w, r, res)
+		return
+	}
 
-	ykey = stocksdb.GetKey("yahoo", mongoDBServerName, mongoDBServerPort, mongoDBAdminUser, mongoDBAdminUserPassword)
-	if ykey.Key == "" {
-		w.Header().Set("Content-Type", "application/json")
-		message := "Error getting API key for Yahoo."
-		json.NewEncoder(w).Encode(&Response{false, message})
-	} else {
-		d = yahoodata.NewData(ykey.Key, key)
-		if d == nil {
-			w.Header().Set("Content-Type", "application/json")
-			message := "Error getting " + key + ". Check Yahoo API."
-			json.NewEncoder(w).Encode(&Response{false, message})
-		} else if len(d.QuoteSummary.Result) == 0 {
-			w.Header().Set("Content-Type", "application/json")
-			message := "Error getting " + key + ". Stock not found."
-			json.NewEncoder(w).Encode(&Response{false, message})
-		} else if d.QuoteSummary.Result[0].AssetProfile.Country == "" {
-			w.Header().Set("Content-Type", "application/json")
-			message := "Error getting " + key + ". Stock not found."
-			json.NewEncoder(w).Encode(&Response{false, message})
-		} else {
-			if stocksdb.FindStock(key, mongoDBServerName, mongoDBServerPort, mongoDBAdminUser, mongoDBAdminUserPassword) {
-				ret = "Stock " + key + " already exists. Updating relevant data"
-				stocksdb.UpdateStock(d, key, mongoDBServerName, mongoDBServerPort, mongoDBAdminUser, mongoDBAdminUserPassword)
-			} else {
-				ret = "Getting and inserting new stock " + key
-				stocksdb.NewStock(d, mongoDBServerName, mongoDBServerPort, mongoDBAdminUser, mongoDBAdminUserPassword)
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(&Response{true, ret})
-		}
+	writeJSON(w, r, http.StatusOK, &ImportResponse{true, res.Message, res.Attempts, requestID(r)})
+}
+
+func importTicker(ctx context.Context, providers *providerSet, ticker string) importResult {
+	atomic.AddInt64(&inFlightImports, 1)
+	defer atomic.AddInt64(&inFlightImports, -1)
+
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "import", trace.WithAttributes(attribute.String("stocks.ticker", ticker)))
+	defer span.End()
+	ctx, il := withImportLog(ctx)
+	res := fetchAndStore(ctx, providers, ticker)
+	recordImport(res)
+	span.SetAttributes(attribute.String("stocks.outcome", res.Status), attribute.Int("stocks.attempts", res.Attempts))
+	if !res.imported() {
+		span.SetStatus(codes.Error, res.Message)
+	}
+
+	il.set("ticker", ticker)
+	il.set("outcome", res.Status)
+	il.set("attempts", res.Attempts)
+	il.stage("duration", start)
+	logger := logging.FromContext(ctx)
+	switch res.Status {
+	case importInserted, importUpdated:
+		logger.Info("import", il.fields)
+	case importNotFound:
+		logger.Warn("import", il.fields)
+	default:
+		logger.Error("import", il.fields)
 	}
+	return res
 }
 
-func getDBCredentials() (string, string) {
-	dbuser, _ := ioutil.ReadFile("/run/secrets/stocksmongouser")
-	dbpass, _ := ioutil.ReadFile("/run/secrets/stocksmongopassword")
+func fetchAndStore(ctx context.Context, providers *providerSet, ticker string) importResult {
+	il := importLogFrom(ctx)
+	p, name := providers.forTicker(ctx, ticker)
+	il.set("provider", name)
+	if p == nil {
+		return importResult{Ticker: ticker, Status: importUnavailable, Message: "Unknown provider " + name + "."}
+	}
+
+	start := time.Now()
+	fetchCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.Provider)
+	f, err := p.Fetch(fetchCtx, ticker)
+	cancel()
+	il.stage("fetch", start)
+	var statusErr *yahoodata.StatusError
+	if err == nil {
+		il.set("upstreamStatus", http.StatusOK)
+	} else if errors.As(err, &statusErr) {
+		il.set("upstreamStatus", statusErr.StatusCode)
+	}
+	if err != nil {
+		il.set("error", err.Error())
+	}
+	if errors.Is(err, marketdata.ErrNoKey) {
+		return importResult{Ticker: ticker, Status: importUnavailable, Message: "Error getting API key for " + name + ".", Attempts: marketdata.Attempts(err)}
+	}
+	if errors.Is(err, breaker.ErrOpen) {
+		return importResult{Ticker: ticker, Status: importUnavailable, Message: "Error getting " + ticker + ". " + err.Error() + "."}
+	}
+	if errors.Is(err, marketdata.ErrNotFound) {
+		return importResult{Ticker: ticker, Status: importNotFound, Message: "Error getting " + ticker + ". Stock not found.", Attempts: marketdata.Attempts(err)}
+	}
+	if err != nil {
+		return importResult{Ticker: ticker, Status: importProviderError, Message: "Error getting " + ticker + ". Check " + p.Name() + " API.", Attempts: marketdata.Attempts(err)}
+	}
 
-	du := string(dbuser)
-	du = strings.TrimRight(du, "\n")
-	dp := string(dbpass)
-	dp = strings.TrimRight(dp, "\n")
+	start = time.Now()
+	writeCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBWrite)
+	err = repo.SaveRawResponse(writeCtx, ticker, p.Name(), time.Now(), f.Raw)
+	cancel()
+	il.stage("saveRawResponse", start)
+	if err != nil {
+		logging.FromContext(ctx).Error("archiving response failed", logging.Fields{"ticker": ticker, "error": err})
+	}
+
+	res, stock := storeStock(ctx, f, ticker)
+	res.Attempts = f.Attempts
+	if stock == nil {
+		return res
+	}
+
+	start = time.Now()
+	writeCtx, cancel = context.WithTimeout(ctx, conf.Timeouts.DBWrite)
+	err = repo.SaveSnapshot(writeCtx, stock)
+	cancel()
+	il.stage("saveSnapshot", start)
+	if err != nil {
+		logging.FromContext(ctx).Error("saving snapshot failed", logging.Fields{"ticker": ticker, "error": err})
+	}
+
+	return res
+}
+
+func storeStock(ctx context.Context, f *marketdata.Fundamentals, ticker string) (importResult, *stocksdb.Stock) {
+	res := importResult{Ticker: ticker, Status: importInserted, Message: "Getting and inserting new stock " + ticker}
+
+	il := importLogFrom(ctx)
+	start := time.Now()
+	readCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBRead)
+	exists, err := repo.FindStock(readCtx, ticker)
+	cancel()
+	il.stage("findStock", start)
+
+	start = time.Now()
+	writeCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.DBWrite)
+	var stock *stocksdb.Stock
+	if err == nil && exists {
+		res = importResult{Ticker: ticker, Status: importUpdated, Message: "Stock " + ticker + " already exists. Updating relevant data"}
+		stock, err = repo.UpdateStock(writeCtx, f, ticker)
+	} else if err == nil {
+		stock, err = repo.NewStock(writeCtx, f)
+	}
+	cancel()
+	il.stage("storeStock", start)
+	if err != nil {
+		logging.FromContext(ctx).Error("storing stock failed", logging.Fields{"ticker": ticker, "error": err})
+		return importResult{Ticker: ticker, Status: importUnavailable, Message: "Error storing " + ticker + ". Check the database."}, nil
+	}
+
+	start = time.Now()
+	competitorsCtx, cancel := context.WithTimeout(ctx, conf.Timeouts.Competitors)
+	err = stocksdb.SetCompetitors(competitorsCtx, stock.Ticker, stock.Exchange)
+	cancel()
+	il.stage("competitors", start)
+	if err != nil {
+		logging.FromContext(ctx).Warn("setting competitors failed", logging.Fields{"ticker": ticker, "error": err})
+	}
+
+	return res, stock
+}
 
-	return du, dp
+func getDBCredentials() (string, string) {
+	return readSecret(conf.MongoDB.User, conf.MongoDB.UserFile), readSecret(conf.MongoDB.Password, conf.MongoDB.PasswordFile)
+}
+
+// readSecret returns value when it is set and the content of file otherwise.
+func readSecret(value, file string) string {
+	if value != "" {
+		return value
+	}
+	b, _ := ioutil.ReadFile(file)
+	return strings.TrimRight(string(b), "\n")
 }
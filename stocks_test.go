@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stocks/marketdata"
+	"stocks/stocksdb"
+	"strings"
+	"testing"
+)
+
+// fakeProvider serves canned fundamentals. Like the real providers it takes
+// a key from its pool before every fetch.
+type fakeProvider struct {
+	keys   marketdata.KeyPool
+	stocks map[string]*marketdata.Fundamentals
+	err    error
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) Fetch(ctx context.Context, ticker string) (*marketdata.Fundamentals, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &marketdata.FetchError{Attempts: 0, Err: err}
+	}
+	if _, err := p.keys.Acquire(ctx); err != nil {
+		return nil, &marketdata.FetchError{Attempts: 0, Err: err}
+	}
+	if p.err != nil {
+		return nil, &marketdata.FetchError{Attempts: 3, Err: p.err}
+	}
+	f, ok := p.stocks[ticker]
+	if !ok {
+		return nil, &marketdata.FetchError{Attempts: 1, Err: marketdata.ErrNotFound}
+	}
+	fetched := *f
+	fetched.Attempts = 1
+	return &fetched, nil
+}
+
+func fakeFundamentals(ticker string) *marketdata.Fundamentals {
+	return &marketdata.Fundamentals{
+		Symbol:   ticker,
+		Name:     ticker + " Inc.",
+		Exchange: "NasdaqGS",
+		Currency: "USD",
+		Raw:      []byte(`{"symbol":"` + ticker + `"}`),
+	}
+}
+
+// setupImport points repo at a MemoryRepository and registers the "fake"
+// provider, answering fetches with fetchErr when it is set. Stored "fake"
+// responses parse back into fakeFundamentals. The
+// competitors service is replaced by a test server.
+func setupImport(t *testing.T, fetchErr error) *stocksdb.MemoryRepository {
+	t.Helper()
+
+	mem := stocksdb.NewMemoryRepository()
+	prevRepo := repo
+	repo = mem
+
+	providerRegistry["fake"] = providerEntry{
+		newProvider: func(keys marketdata.KeyPool) marketdata.FundamentalsProvider {
+			return &fakeProvider{
+				keys:   keys,
+				stocks: map[string]*marketdata.Fundamentals{"AAPL": fakeFundamentals("AAPL")},
+				err:    fetchErr,
+			}
+		},
+		parse: func(body []byte) (*marketdata.Fundamentals, error) {
+			var raw struct {
+				Symbol string `json:"symbol"`
+			}
+			if err := json.Unmarshal(body, &raw); err != nil {
+				return nil, err
+			}
+			return fakeFundamentals(raw.Symbol), nil
+		},
+	}
+
+	competitors := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	prevAddress := stocksdb.CompetitorsAddress
+	stocksdb.CompetitorsAddress = strings.TrimPrefix(competitors.URL, "http://")
+
+	t.Cleanup(func() {
+		repo = prevRepo
+		delete(providerRegistry, "fake")
+		stocksdb.CompetitorsAddress = prevAddress
+		competitors.Close()
+	})
+	return mem
+}
+
+func addFakeKey(t *testing.T, mem *stocksdb.MemoryRepository) {
+	t.Helper()
+	if err := mem.InsertKey(context.Background(), &stocksdb.Key{Name: "fake", Key: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type importReply struct {
+	Success  bool   `json:"status"`
+	Message  string `json:"message"`
+	Code     string `json:"code"`
+	Attempts int    `json:"attempts"`
+}
+
+func postImport(t *testing.T, ticker string) (int, importReply) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/import/"+ticker+"?provider=fake", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+
+	var reply importReply
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, reply
+}
+
+func TestImportStockInserted(t *testing.T) {
+	mem := setupImport(t, nil)
+	addFakeKey(t, mem)
+
+	status, reply := postImport(t, "aapl")
+	if status != http.StatusOK || !reply.Success {
+		t.Fatalf("got %d %+v, want 200 with status true", status, reply)
+	}
+	if reply.Message != "Getting and inserting new stock AAPL" {
+		t.Errorf("message = %q", reply.Message)
+	}
+	if reply.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1", reply.Attempts)
+	}
+
+	stock, err := mem.GetStock(context.Background(), "AAPL")
+	if err != nil || stock == nil {
+		t.Fatalf("stored stock = %v, %v", stock, err)
+	}
+	if stock.Name != "AAPL Inc." || stock.Exchange != "NasdaqGS" {
+		t.Errorf("stored stock has name %q and exchange %q", stock.Name, stock.Exchange)
+	}
+	if n := len(mem.RawResponses("AAPL")); n != 1 {
+		t.Errorf("%d raw responses stored, want 1", n)
+	}
+	if n := len(mem.Snapshots("AAPL")); n != 1 {
+		t.Errorf("%d snapshots stored, want 1", n)
+	}
+}
+
+func TestImportStockUpdated(t *testing.T) {
+	mem := setupImport(t, nil)
+	addFakeKey(t, mem)
+	if _, err := mem.NewStock(context.Background(), fakeFundamentals("AAPL")); err != nil {
+		t.Fatal(err)
+	}
+
+	status, reply := postImport(t, "AAPL")
+	if status != http.StatusOK || !reply.Success {
+		t.Fatalf("got %d %+v, want 200 with status true", status, reply)
+	}
+	if reply.Message != "Stock AAPL already exists. Updating relevant data" {
+		t.Errorf("message = %q", reply.Message)
+	}
+	if n := len(mem.Snapshots("AAPL")); n != 1 {
+		t.Errorf("%d snapshots stored, want 1", n)
+	}
+}
+
+func TestImportStockErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ticker   string
+		fetchErr error
+		noKey    bool
+		status   int
+		code     string
+		attempts int
+	}{
+		{"not found", "MSFT", nil, false, http.StatusNotFound, errCodeNotFound, 1},
+		{"provider error", "AAPL", errors.New("connection reset"), false, http.StatusBadGateway, errCodeUpstream, 3},
+		{"missing key", "AAPL", nil, true, http.StatusServiceUnavailable, errCodeUnavailable, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := setupImport(t, tt.fetchErr)
+			if !tt.noKey {
+				addFakeKey(t, mem)
+			}
+
+			status, reply := postImport(t, tt.ticker)
+			if status != tt.status || reply.Success || reply.Code != tt.code {
+				t.Fatalf("got %d %+v, want %d with code %s", status, reply, tt.status, tt.code)
+			}
+			if reply.Attempts != tt.attempts {
+				t.Errorf("attempts = %d, want %d", reply.Attempts, tt.attempts)
+			}
+			if found, _ := mem.FindStock(context.Background(), tt.ticker); found {
+				t.Errorf("%s was stored", tt.ticker)
+			}
+		})
+	}
+}
+
+func TestImportStockAsync(t *testing.T) {
+	mem := setupImport(t, nil)
+	addFakeKey(t, mem)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/import/AAPL?provider=fake&async=true", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	var reply JobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	if rec.Code != http.StatusAccepted || reply.Job == nil || reply.Job.State != stocksdb.JobQueued {
+		t.Fatalf("got %d %s, want 202 with a queued job", rec.Code, rec.Body.String())
+	}
+	if id := rec.Header().Get("X-Request-ID"); id == "" || reply.RequestID != id {
+		t.Errorf("requestId = %q, want the X-Request-ID %q", reply.RequestID, id)
+	}
+
+	job, err := mem.ClaimJob(context.Background())
+	if err != nil || job == nil {
+		t.Fatalf("claimed job = %v, %v", job, err)
+	}
+	runJob(context.Background(), job)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/jobs/"+job.ID.Hex(), nil)
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	reply = JobResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+	if rec.Code != http.StatusOK || reply.Job == nil || reply.Job.State != stocksdb.JobSucceeded || reply.Job.Result != importInserted {
+		t.Fatalf("got %d %s, want 200 with a succeeded insert", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRunJobRequeuedOnShutdown(t *testing.T) {
+	mem := setupImport(t, nil)
+	addFakeKey(t, mem)
+	if _, err := mem.NewJob(context.Background(), "AAPL", "fake"); err != nil {
+		t.Fatal(err)
+	}
+	job, err := mem.ClaimJob(context.Background())
+	if err != nil || job == nil {
+		t.Fatalf("claimed job = %v, %v", job, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runJob(ctx, job)
+
+	stored, err := mem.GetJob(context.Background(), job.ID.Hex())
+	if err != nil || stored == nil {
+		t.Fatalf("stored job = %v, %v", stored, err)
+	}
+	if stored.State != stocksdb.JobQueued || stored.Attempts != 0 {
+		t.Errorf("state = %s after %d attempts, want %s after 0", stored.State, stored.Attempts, stocksdb.JobQueued)
+	}
+	if found, _ := mem.FindStock(context.Background(), "AAPL"); found {
+		t.Error("AAPL was stored")
+	}
+}
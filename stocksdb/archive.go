@@ -0,0 +1,89 @@
+package stocksdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrStockExists = errors.New("stock already exists")
+
+type ArchivedStock struct {
+	Stock        `bson:",inline"`
+	DeletedAt    time.Time `json:"deletedat" bson:"deletedat"`
+	DeleteReason string    `json:"deletereason" bson:"deletereason"`
+}
+
+var archiveColl = "stocks_archive"
+
+// ArchiveStock moves the stock document into the archive collection. It
+// returns false when the ticker is not stored.
+func (s *Store) ArchiveStock(ctx context.Context, ticker, reason string) (bool, error) {
+	var stock Stock
+	stocks := s.db.Collection(stocksColl)
+	err := stocks.FindOne(ctx, bson.M{"ticker": ticker}).Decode(&stock)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	archived := ArchivedStock{Stock: stock, DeletedAt: time.Now(), DeleteReason: reason}
+	archive := s.db.Collection(archiveColl)
+	if _, err := archive.InsertOne(ctx, archived); err != nil {
+		return false, err
+	}
+	if _, err := stocks.DeleteOne(ctx, bson.M{"_id": stock.ID}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RestoreStock moves the most recently archived document of the ticker back
+// into the stocks collection. It returns false when nothing is archived and
+// ErrStockExists when the ticker was imported again in the meantime.
+func (s *Store) RestoreStock(ctx context.Context, ticker string) (bool, error) {
+	stocks := s.db.Collection(stocksColl)
+	n, err := stocks.CountDocuments(ctx, bson.M{"ticker": ticker})
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return false, ErrStockExists
+	}
+
+	archived, err := s.GetArchivedStock(ctx, ticker)
+	if err != nil || archived == nil {
+		return false, err
+	}
+
+	if _, err := stocks.InsertOne(ctx, archived.Stock); err != nil {
+		return false, err
+	}
+	archive := s.db.Collection(archiveColl)
+	if _, err := archive.DeleteOne(ctx, bson.M{"_id": archived.ID}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Store) GetArchivedStock(ctx context.Context, ticker string) (*ArchivedStock, error) {
+	var archived ArchivedStock
+	archive := s.db.Collection(archiveColl)
+	opts := options.FindOne().SetSort(bson.D{{Key: "deletedat", Value: -1}})
+	err := archive.FindOne(ctx, bson.M{"ticker": ticker}, opts).Decode(&archived)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &archived, nil
+}
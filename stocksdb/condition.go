@@ -0,0 +1,45 @@
+package stocksdb
+
+// Condition is a parsed screen expression: an And, Or or Not of other
+// conditions, or a Comparison of one stock field with a value. The screener
+// package builds conditions, Store translates them into MongoDB filters and
+// MemoryRepository evaluates them directly.
+type Condition interface {
+	condition()
+}
+
+// And matches the stocks that match all of its terms.
+type And []Condition
+
+// Or matches the stocks that match at least one of its terms.
+type Or []Condition
+
+// Not matches the stocks that do not match its condition.
+type Not struct {
+	Condition Condition
+}
+
+type CompareOp string
+
+const (
+	OpEq  CompareOp = "="
+	OpNe  CompareOp = "!="
+	OpLt  CompareOp = "<"
+	OpLte CompareOp = "<="
+	OpGt  CompareOp = ">"
+	OpGte CompareOp = ">="
+)
+
+// Comparison compares the top level Stock field with the given json name to
+// Value, which is a float64, string or time.Time following the field kind.
+// A field without a value, like a null ratio, only matches OpNe.
+type Comparison struct {
+	Field string
+	Op    CompareOp
+	Value interface{}
+}
+
+func (And) condition()        {}
+func (Or) condition()         {}
+func (Not) condition()        {}
+func (Comparison) condition() {}
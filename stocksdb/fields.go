@@ -0,0 +1,76 @@
+package stocksdb
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+type FieldKind int
+
+const (
+	FieldOther FieldKind = iota
+	FieldString
+	FieldNumber
+	FieldTime
+)
+
+type stockField struct {
+	kind  FieldKind
+	index int
+}
+
+var stockFields = getStockFields()
+
+func getStockFields() map[string]stockField {
+	fields := make(map[string]stockField)
+
+	t := reflect.TypeOf(Stock{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jsonName := strings.Split(f.Tag.Get("json"), ",")[0]
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+
+		kind := FieldOther
+		switch {
+		case f.Type == reflect.TypeOf(time.Time{}):
+			kind = FieldTime
+		case f.Type.Kind() == reflect.String:
+			kind = FieldString
+		case f.Type.Kind() == reflect.Int64 || f.Type.Kind() == reflect.Float64:
+			kind = FieldNumber
+		}
+		fields[jsonName] = stockField{kind, i}
+	}
+
+	return fields
+}
+
+// StockField reports the kind of the top level Stock field with the given
+// json name and whether such a field exists.
+func StockField(name string) (FieldKind, bool) {
+	f, ok := stockFields[name]
+	return f.kind, ok
+}
+
+// stockFieldValue returns the top level field of stock with the given json
+// name.
+func stockFieldValue(stock *Stock, name string) (reflect.Value, bool) {
+	f, ok := stockFields[name]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(stock).Elem().Field(f.index), true
+}
+
+func StockFieldNames() []string {
+	names := make([]string, 0, len(stockFields))
+	for name := range stockFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,156 @@
+package stocksdb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+type Job struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Ticker     string             `bson:"ticker" json:"ticker"`
+	Provider   string             `bson:"provider" json:"provider,omitempty"`
+	State      string             `bson:"state" json:"state"`
+	Attempts   int                `bson:"attempts" json:"attempts"`
+	Result     string             `bson:"result" json:"result,omitempty"`
+	Message    string             `bson:"message" json:"message,omitempty"`
+	Error      string             `bson:"error" json:"error,omitempty"`
+	CreatedAt  time.Time          `bson:"createdat" json:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updatedat" json:"updatedAt"`
+	RunAfter   time.Time          `bson:"runafter" json:"-"`
+	StartedAt  *time.Time         `bson:"startedat,omitempty" json:"startedAt,omitempty"`
+	FinishedAt *time.Time         `bson:"finishedat,omitempty" json:"finishedAt,omitempty"`
+}
+
+var jobsColl = "jobs"
+
+func (s *Store) NewJob(ctx context.Context, ticker, provider string) (*Job, error) {
+	now := time.Now()
+	job := &Job{
+		Ticker:    ticker,
+		Provider:  provider,
+		State:     JobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+		RunAfter:  now,
+	}
+
+	collection := s.db.Collection(jobsColl)
+	res, err := collection.InsertOne(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = res.InsertedID.(primitive.ObjectID)
+
+	return job, nil
+}
+
+func (s *Store) GetJob(ctx context.Context, id string) (*Job, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var job Job
+	collection := s.db.Collection(jobsColl)
+	err = collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimJob atomically moves the oldest runnable queued job to the running
+// state and returns it. It returns nil when the queue is empty.
+func (s *Store) ClaimJob(ctx context.Context) (*Job, error) {
+	now := time.Now()
+	filter := bson.M{"state": JobQueued, "runafter": bson.M{"$lte": now}}
+	update := bson.M{
+		"$set": bson.M{"state": JobRunning, "startedat": now, "updatedat": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "createdat", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job Job
+	collection := s.db.Collection(jobsColl)
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *Store) FinishJob(ctx context.Context, id primitive.ObjectID, state, result, message, jobErr string) error {
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"state":      state,
+		"result":     result,
+		"message":    message,
+		"error":      jobErr,
+		"updatedat":  now,
+		"finishedat": now,
+	}}
+
+	collection := s.db.Collection(jobsColl)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+func (s *Store) RetryJob(ctx context.Context, id primitive.ObjectID, jobErr string, runAfter time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"state":     JobQueued,
+		"error":     jobErr,
+		"updatedat": time.Now(),
+		"runafter":  runAfter,
+	}}
+
+	collection := s.db.Collection(jobsColl)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// ReleaseJob puts a running job back in the queue without counting the
+// attempt, for a job that was cut off by shutdown.
+func (s *Store) ReleaseJob(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{"state": JobQueued, "updatedat": time.Now(), "runafter": time.Now()},
+		"$inc": bson.M{"attempts": -1},
+	}
+	collection := s.db.Collection(jobsColl)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id, "state": JobRunning}, update)
+	return err
+}
+
+// RequeueStaleJobs puts back jobs left running for longer than maxAge, for
+// example by a worker that was killed mid-import.
+func (s *Store) RequeueStaleJobs(ctx context.Context, maxAge time.Duration) (int64, error) {
+	now := time.Now()
+	filter := bson.M{"state": JobRunning, "startedat": bson.M{"$lt": now.Add(-maxAge)}}
+	update := bson.M{"$set": bson.M{"state": JobQueued, "updatedat": now, "runafter": now}}
+
+	collection := s.db.Collection(jobsColl)
+	res, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
@@ -0,0 +1,147 @@
+package stocksdb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultKeyWindow = 24 * time.Hour
+
+func (k *Key) window() time.Duration {
+	if k.WindowSeconds <= 0 {
+		return defaultKeyWindow
+	}
+	return time.Duration(k.WindowSeconds) * time.Second
+}
+
+// available tells whether the key can be used at now without resetting
+// its window.
+func (k *Key) available() bool {
+	return !k.Disabled && !k.Exhausted && (k.Limit == 0 || k.Used < k.Limit)
+}
+
+// AcquireKey picks the least used key of the provider that has budget left
+// and counts one fetch against it. A key whose window is over starts a new
+// one. It returns nil when every key is used up.
+func (s *Store) AcquireKey(ctx context.Context, name string) (*Key, error) {
+	collection := s.db.Collection(keyColl)
+	cursor, err := collection.Find(ctx, bson.M{"name": name, "disabled": bson.M{"$ne": true}}, options.Find().SetSort(bson.D{{Key: "used", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var keys []Key
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, k := range keys {
+		var filter, update bson.M
+		if !k.ResetAt.After(now) {
+			filter = bson.M{"_id": k.ID, "disabled": bson.M{"$ne": true}, "$or": bson.A{
+				bson.M{"resetat": bson.M{"$lte": now}},
+				bson.M{"resetat": bson.M{"$exists": false}},
+			}}
+			update = bson.M{"$set": bson.M{"used": 1, "exhausted": false, "resetat": now.Add(k.window())}}
+		} else if k.available() {
+			filter = bson.M{"_id": k.ID, "resetat": bson.M{"$gt": now}, "exhausted": bson.M{"$ne": true}, "disabled": bson.M{"$ne": true}}
+			if k.Limit > 0 {
+				filter["used"] = bson.M{"$lt": k.Limit}
+			}
+			update = bson.M{"$inc": bson.M{"used": 1}}
+		} else {
+			continue
+		}
+
+		var acquired Key
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&acquired)
+		if err == mongo.ErrNoDocuments {
+			// Another request took the last of its budget.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &acquired, nil
+	}
+	return nil, nil
+}
+
+// MarkKeyExhausted keeps the key out of AcquireKey until its window resets.
+func (s *Store) MarkKeyExhausted(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	collection := s.db.Collection(keyColl)
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"exhausted": true}})
+	return err
+}
+
+// InsertKey stores a new key and sets its ID.
+func (s *Store) InsertKey(ctx context.Context, key *Key) error {
+	collection := s.db.Collection(keyColl)
+	res, err := collection.InsertOne(ctx, key)
+	if err != nil {
+		return err
+	}
+	key.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// ListKeys returns the keys of the provider, or of all providers when name
+// is empty.
+func (s *Store) ListKeys(ctx context.Context, name string) ([]Key, error) {
+	filter := bson.M{}
+	if name != "" {
+		filter["name"] = name
+	}
+
+	collection := s.db.Collection(keyColl)
+	cur, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	keys := []Key{}
+	if err := cur.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SetKeyDisabled returns false when there is no key with that ID.
+func (s *Store) SetKeyDisabled(ctx context.Context, id string, disabled bool) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, nil
+	}
+
+	collection := s.db.Collection(keyColl)
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"disabled": disabled}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// DeleteKey returns false when there is no key with that ID.
+func (s *Store) DeleteKey(ctx context.Context, id string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, nil
+	}
+
+	collection := s.db.Collection(keyColl)
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
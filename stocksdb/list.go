@@ -0,0 +1,136 @@
+package stocksdb
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrInvalidSort = errors.New("invalid sort field")
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+var StockFilterFields = []string{"sector", "industry", "country", "exchange", "currency"}
+
+type StockQuery struct {
+	Filters    map[string]string
+	SortField  string
+	Descending bool
+	Limit      int64
+	Cursor     string
+}
+
+type listCursor struct {
+	SortField  string             `bson:"s"`
+	Descending bool               `bson:"d"`
+	Value      bson.RawValue      `bson:"v"`
+	ID         primitive.ObjectID `bson:"id"`
+}
+
+// ListStocks returns one page of stocks matching q, ordered by q.SortField
+// and then by _id, together with the cursor of the next page. The cursor is
+// empty on the last page.
+func (s *Store) ListStocks(ctx context.Context, q StockQuery) ([]Stock, string, error) {
+	if q.SortField == "" {
+		q.SortField = "ticker"
+	}
+	if kind, ok := StockField(q.SortField); !ok || q.SortField == "id" || kind == FieldOther {
+		return nil, "", ErrInvalidSort
+	}
+
+	filter := bson.M{}
+	for _, f := range StockFilterFields {
+		if v, ok := q.Filters[f]; ok && v != "" {
+			filter[f] = v
+		}
+	}
+
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil || c.SortField != q.SortField || c.Descending != q.Descending {
+			return nil, "", ErrInvalidCursor
+		}
+		filter = bson.M{"$and": bson.A{filter, afterCursor(c)}}
+	}
+
+	order := 1
+	if q.Descending {
+		order = -1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: q.SortField, Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(q.Limit + 1)
+
+	collection := s.db.Collection(stocksColl)
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	var stocks []Stock
+	if err := cur.All(ctx, &stocks); err != nil {
+		return nil, "", err
+	}
+
+	if int64(len(stocks)) <= q.Limit {
+		return stocks, "", nil
+	}
+	stocks = stocks[:q.Limit]
+	next, err := encodeCursor(stocks[len(stocks)-1], q)
+	if err != nil {
+		return nil, "", err
+	}
+	return stocks, next, nil
+}
+
+// afterCursor matches the documents that sort after the cursor. Null sorts
+// before all other values, but comparison operators never match across
+// types, so null needs its own conditions.
+func afterCursor(c *listCursor) bson.M {
+	op := "$gt"
+	if c.Descending {
+		op = "$lt"
+	}
+	after := bson.A{bson.M{c.SortField: c.Value, "_id": bson.M{op: c.ID}}}
+	switch {
+	case c.Value.Type == bsontype.Null && !c.Descending:
+		after = append(after, bson.M{c.SortField: bson.M{"$ne": nil}})
+	case c.Value.Type != bsontype.Null && c.Descending:
+		after = append(after, bson.M{c.SortField: bson.M{op: c.Value}}, bson.M{c.SortField: nil})
+	case c.Value.Type != bsontype.Null:
+		after = append(after, bson.M{c.SortField: bson.M{op: c.Value}})
+	}
+	return bson.M{"$or": after}
+}
+
+func encodeCursor(last Stock, q StockQuery) (string, error) {
+	doc, err := bson.Marshal(last)
+	if err != nil {
+		return "", err
+	}
+	value, err := bson.Raw(doc).LookupErr(q.SortField)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := bson.Marshal(listCursor{q.SortField, q.Descending, value, last.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (*listCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c listCursor
+	if err := bson.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,838 @@
+package stocksdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"stocks/marketdata"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository keeps all data of a StockRepository in process memory.
+// It is meant for tests and local runs without MongoDB.
+type MemoryRepository struct {
+	mu           sync.Mutex
+	stocks       map[string]Stock
+	archive      []ArchivedStock
+	keys         []Key
+	rawResponses []RawResponse
+	snapshots    []Stock
+	jobs         []Job
+	screens      map[string]Screen
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		stocks:  make(map[string]Stock),
+		screens: make(map[string]Screen),
+	}
+}
+
+func (m *MemoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// RawResponses returns the stored raw responses of ticker, oldest first.
+func (m *MemoryRepository) RawResponses(ticker string) []RawResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []RawResponse
+	for _, raw := range m.rawResponses {
+		if raw.Ticker == ticker {
+			res = append(res, raw)
+		}
+	}
+	return res
+}
+
+// Snapshots returns the recorded snapshots of ticker, oldest first.
+func (m *MemoryRepository) Snapshots(ticker string) []Stock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []Stock
+	for _, s := range m.snapshots {
+		if s.Ticker == ticker {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func (m *MemoryRepository) FindStock(ctx context.Context, ticker string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.stocks[ticker]
+	return ok, nil
+}
+
+func (m *MemoryRepository) GetStock(ctx context.Context, ticker string) (*Stock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stock, ok := m.stocks[ticker]
+	if !ok {
+		return nil, nil
+	}
+	return cloneStock(stock)
+}
+
+func (m *MemoryRepository) NewStock(ctx context.Context, f *marketdata.Fundamentals) (*Stock, error) {
+	var stock Stock
+	stock.ID = primitive.NewObjectID()
+	stock.Ticker = f.Symbol
+	setStockData(f, &stock)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.stocks[stock.Ticker]; ok {
+		return nil, ErrStockExists
+	}
+	m.stocks[stock.Ticker] = stock
+	return cloneStock(stock)
+}
+
+func (m *MemoryRepository) UpdateStock(ctx context.Context, f *marketdata.Fundamentals, ticker string) (*Stock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.stocks[ticker]
+	if !ok {
+		return nil, errors.New("stock " + ticker + " should exist but was not found")
+	}
+	currentStock, err := cloneStock(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	setStockData(f, currentStock)
+	m.stocks[ticker] = *currentStock
+	return cloneStock(*currentStock)
+}
+
+func (m *MemoryRepository) GetKey(ctx context.Context, name string) (*Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range m.keys {
+		if key.Name == name && !key.Disabled {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MemoryRepository) AcquireKey(ctx context.Context, name string) (*Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var best *Key
+	for i := range m.keys {
+		k := &m.keys[i]
+		if k.Name != name {
+			continue
+		}
+		if !k.ResetAt.After(now) {
+			k.Used = 0
+			k.Exhausted = false
+			k.ResetAt = now.Add(k.window())
+		}
+		if k.available() && (best == nil || k.Used < best.Used) {
+			best = k
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	best.Used++
+	acquired := *best
+	return &acquired, nil
+}
+
+func (m *MemoryRepository) MarkKeyExhausted(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.keys {
+		if m.keys[i].ID.Hex() == id {
+			m.keys[i].Exhausted = true
+		}
+	}
+	return nil
+}
+
+// ListStocks follows the ordering and cursor format of Store.ListStocks.
+func (m *MemoryRepository) ListStocks(ctx context.Context, q StockQuery) ([]Stock, string, error) {
+	if q.SortField == "" {
+		q.SortField = "ticker"
+	}
+	if kind, ok := StockField(q.SortField); !ok || q.SortField == "id" || kind == FieldOther {
+		return nil, "", ErrInvalidSort
+	}
+
+	var after *listCursor
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil || c.SortField != q.SortField || c.Descending != q.Descending {
+			return nil, "", ErrInvalidCursor
+		}
+		after = c
+	}
+
+	type entry struct {
+		stock Stock
+		value bson.RawValue
+	}
+
+	m.mu.Lock()
+	var entries []entry
+	for _, stock := range m.stocks {
+		if !matchesFilters(stock, q.Filters) {
+			continue
+		}
+		value, err := stockValue(stock, q.SortField)
+		if err != nil {
+			m.mu.Unlock()
+			return nil, "", err
+		}
+		entries = append(entries, entry{stock, value})
+	}
+	m.mu.Unlock()
+
+	less := func(a, b entry) bool {
+		c := compareValues(a.value, b.value)
+		if c == 0 {
+			c = bytes.Compare(a.stock.ID[:], b.stock.ID[:])
+		}
+		if q.Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+	sort.Slice(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+
+	var stocks []Stock
+	for _, e := range entries {
+		if after != nil && !less(entry{Stock{ID: after.ID}, after.Value}, e) {
+			continue
+		}
+		stock, err := cloneStock(e.stock)
+		if err != nil {
+			return nil, "", err
+		}
+		stocks = append(stocks, *stock)
+	}
+
+	if int64(len(stocks)) <= q.Limit {
+		return stocks, "", nil
+	}
+	stocks = stocks[:q.Limit]
+	next, err := encodeCursor(stocks[len(stocks)-1], q)
+	if err != nil {
+		return nil, "", err
+	}
+	return stocks, next, nil
+}
+
+func (m *MemoryRepository) SaveRawResponse(ctx context.Context, ticker, provider string, fetchedAt time.Time, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rawResponses = append(m.rawResponses, RawResponse{
+		Ticker:    ticker,
+		Provider:  provider,
+		FetchedAt: fetchedAt,
+		Body:      append([]byte(nil), body...),
+	})
+	return nil
+}
+
+func (m *MemoryRepository) SaveSnapshot(ctx context.Context, stock *Stock) error {
+	snapshot, err := cloneStock(*stock)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.snapshots = append(m.snapshots, *snapshot)
+	return nil
+}
+
+func (m *MemoryRepository) ScreenStocks(ctx context.Context, cond Condition, limit int64) ([]Stock, error) {
+	m.mu.Lock()
+	var matched []Stock
+	for _, stock := range m.stocks {
+		if matchesCondition(&stock, cond) {
+			matched = append(matched, stock)
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Ticker < matched[j].Ticker })
+	if limit > 0 && int64(len(matched)) > limit {
+		matched = matched[:limit]
+	}
+	stocks := make([]Stock, 0, len(matched))
+	for _, s := range matched {
+		stock, err := cloneStock(s)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, *stock)
+	}
+	return stocks, nil
+}
+
+func (m *MemoryRepository) ArchiveStock(ctx context.Context, ticker, reason string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stock, ok := m.stocks[ticker]
+	if !ok {
+		return false, nil
+	}
+	m.archive = append(m.archive, ArchivedStock{Stock: stock, DeletedAt: time.Now(), DeleteReason: reason})
+	delete(m.stocks, ticker)
+	return true, nil
+}
+
+func (m *MemoryRepository) RestoreStock(ctx context.Context, ticker string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.stocks[ticker]; ok {
+		return false, ErrStockExists
+	}
+	i := m.lastArchived(ticker)
+	if i < 0 {
+		return false, nil
+	}
+	m.stocks[ticker] = m.archive[i].Stock
+	m.archive = append(m.archive[:i], m.archive[i+1:]...)
+	return true, nil
+}
+
+func (m *MemoryRepository) GetArchivedStock(ctx context.Context, ticker string) (*ArchivedStock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.lastArchived(ticker)
+	if i < 0 {
+		return nil, nil
+	}
+	stock, err := cloneStock(m.archive[i].Stock)
+	if err != nil {
+		return nil, err
+	}
+	archived := m.archive[i]
+	archived.Stock = *stock
+	return &archived, nil
+}
+
+// lastArchived returns the index of the most recently archived document of
+// ticker, or -1.
+func (m *MemoryRepository) lastArchived(ticker string) int {
+	found := -1
+	for i, a := range m.archive {
+		if a.Ticker == ticker && (found < 0 || !a.DeletedAt.Before(m.archive[found].DeletedAt)) {
+			found = i
+		}
+	}
+	return found
+}
+
+func (m *MemoryRepository) InsertKey(ctx context.Context, key *Key) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key.ID = primitive.NewObjectID()
+	m.keys = append(m.keys, *key)
+	return nil
+}
+
+func (m *MemoryRepository) ListKeys(ctx context.Context, name string) ([]Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := []Key{}
+	for _, k := range m.keys {
+		if name == "" || k.Name == name {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return bytes.Compare(keys[i].ID[:], keys[j].ID[:]) < 0
+	})
+	return keys, nil
+}
+
+func (m *MemoryRepository) SetKeyDisabled(ctx context.Context, id string, disabled bool) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.keys {
+		if m.keys[i].ID.Hex() == id {
+			m.keys[i].Disabled = disabled
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MemoryRepository) DeleteKey(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.keys {
+		if m.keys[i].ID.Hex() == id {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MemoryRepository) GetRawResponse(ctx context.Context, ticker string, at time.Time) (*RawResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var found *RawResponse
+	for i := range m.rawResponses {
+		raw := &m.rawResponses[i]
+		if raw.Ticker != ticker || (!at.IsZero() && raw.FetchedAt.After(at)) {
+			continue
+		}
+		if found == nil || !raw.FetchedAt.Before(found.FetchedAt) {
+			found = raw
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	raw := *found
+	raw.Body = append([]byte(nil), found.Body...)
+	return &raw, nil
+}
+
+func (m *MemoryRepository) RawResponseTickers(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool)
+	tickers := []string{}
+	for _, raw := range m.rawResponses {
+		if !seen[raw.Ticker] {
+			seen[raw.Ticker] = true
+			tickers = append(tickers, raw.Ticker)
+		}
+	}
+	sort.Strings(tickers)
+	return tickers, nil
+}
+
+func (m *MemoryRepository) GetHistory(ctx context.Context, ticker, field string, from, to time.Time) ([]HistoryPoint, error) {
+	if !HistoryField(field) {
+		return nil, errors.New("field " + field + " is not recorded in snapshots")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	points := []HistoryPoint{}
+	for _, s := range m.snapshots {
+		ts := s.LastUpdated
+		if s.Ticker != ticker || (!from.IsZero() && ts.Before(from)) || (!to.IsZero() && !ts.Before(to)) {
+			continue
+		}
+		value, err := stockValue(s, field)
+		if err != nil {
+			return nil, err
+		}
+		p := HistoryPoint{Time: ts}
+		if value.Type == bsontype.EmbeddedDocument {
+			var doc bson.M
+			err = value.Unmarshal(&doc)
+			p.Value = doc
+		} else {
+			err = value.Unmarshal(&p.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, nil
+}
+
+func (m *MemoryRepository) NewJob(ctx context.Context, ticker, provider string) (*Job, error) {
+	now := time.Now()
+	job := Job{
+		ID:        primitive.NewObjectID(),
+		Ticker:    ticker,
+		Provider:  provider,
+		State:     JobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+		RunAfter:  now,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs = append(m.jobs, job)
+	return &job, nil
+}
+
+func (m *MemoryRepository) GetJob(ctx context.Context, id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range m.jobs {
+		if job.ID.Hex() == id {
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+// ClaimJob takes the oldest runnable queued job like Store.ClaimJob.
+func (m *MemoryRepository) ClaimJob(ctx context.Context) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var claimed *Job
+	for i := range m.jobs {
+		job := &m.jobs[i]
+		if job.State != JobQueued || job.RunAfter.After(now) {
+			continue
+		}
+		if claimed == nil || job.CreatedAt.Before(claimed.CreatedAt) {
+			claimed = job
+		}
+	}
+	if claimed == nil {
+		return nil, nil
+	}
+
+	claimed.State = JobRunning
+	claimed.StartedAt = &now
+	claimed.UpdatedAt = now
+	claimed.Attempts++
+	job := *claimed
+	return &job, nil
+}
+
+func (m *MemoryRepository) FinishJob(ctx context.Context, id primitive.ObjectID, state, result, message, jobErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if job := m.job(id); job != nil {
+		job.State = state
+		job.Result = result
+		job.Message = message
+		job.Error = jobErr
+		job.UpdatedAt = now
+		job.FinishedAt = &now
+	}
+	return nil
+}
+
+func (m *MemoryRepository) RetryJob(ctx context.Context, id primitive.ObjectID, jobErr string, runAfter time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job := m.job(id); job != nil {
+		job.State = JobQueued
+		job.Error = jobErr
+		job.UpdatedAt = time.Now()
+		job.RunAfter = runAfter
+	}
+	return nil
+}
+
+func (m *MemoryRepository) ReleaseJob(ctx context.Context, id primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job := m.job(id); job != nil && job.State == JobRunning {
+		now := time.Now()
+		job.State = JobQueued
+		job.UpdatedAt = now
+		job.RunAfter = now
+		job.Attempts--
+	}
+	return nil
+}
+
+func (m *MemoryRepository) RequeueStaleJobs(ctx context.Context, maxAge time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for i := range m.jobs {
+		job := &m.jobs[i]
+		if job.State == JobRunning && job.StartedAt != nil && job.StartedAt.Before(now.Add(-maxAge)) {
+			job.State = JobQueued
+			job.UpdatedAt = now
+			job.RunAfter = now
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryRepository) job(id primitive.ObjectID) *Job {
+	for i := range m.jobs {
+		if m.jobs[i].ID == id {
+			return &m.jobs[i]
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepository) SaveScreen(ctx context.Context, screen *Screen) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	stored, ok := m.screens[screen.Name]
+	if !ok {
+		stored = Screen{Name: screen.Name, CreatedAt: now}
+	}
+	stored.Expression = screen.Expression
+	stored.Columns = append([]string(nil), screen.Columns...)
+	stored.UpdatedAt = now
+	m.screens[screen.Name] = stored
+	return nil
+}
+
+func (m *MemoryRepository) GetScreen(ctx context.Context, name string) (*Screen, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	screen, ok := m.screens[name]
+	if !ok {
+		return nil, nil
+	}
+	return &screen, nil
+}
+
+func (m *MemoryRepository) ListScreens(ctx context.Context) ([]Screen, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	screens := []Screen{}
+	for _, s := range m.screens {
+		screens = append(screens, s)
+	}
+	sort.Slice(screens, func(i, j int) bool { return screens[i].Name < screens[j].Name })
+	return screens, nil
+}
+
+func (m *MemoryRepository) DeleteScreen(ctx context.Context, name string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.screens[name]
+	delete(m.screens, name)
+	return ok, nil
+}
+
+// cloneStock deep copies the stock so callers cannot change stored data.
+func cloneStock(stock Stock) (*Stock, error) {
+	b, err := bson.Marshal(stock)
+	if err != nil {
+		return nil, err
+	}
+	var clone Stock
+	if err := bson.Unmarshal(b, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+func matchesFilters(stock Stock, filters map[string]string) bool {
+	for _, f := range StockFilterFields {
+		v, ok := filters[f]
+		if !ok || v == "" {
+			continue
+		}
+		value, err := stockValue(stock, f)
+		if err != nil || value.StringValue() != v {
+			return false
+		}
+	}
+	return true
+}
+
+func stockValue(stock Stock, field string) (bson.RawValue, error) {
+	doc, err := bson.Marshal(stock)
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	return bson.Raw(doc).LookupErr(field)
+}
+
+// compareValues orders null before all other values, as MongoDB does.
+func compareValues(a, b bson.RawValue) int {
+	if a.Type == bsontype.Null || b.Type == bsontype.Null {
+		return nullRank(a) - nullRank(b)
+	}
+	if af, ok := numberValue(a); ok {
+		if bf, ok := numberValue(b); ok {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	if a.Type == bsontype.DateTime && b.Type == bsontype.DateTime {
+		at, bt := a.DateTime(), b.DateTime()
+		switch {
+		case at < bt:
+			return -1
+		case at > bt:
+			return 1
+		}
+		return 0
+	}
+	if a.Type == bsontype.String && b.Type == bsontype.String {
+		return strings.Compare(a.StringValue(), b.StringValue())
+	}
+	return int(a.Type) - int(b.Type)
+}
+
+func nullRank(v bson.RawValue) int {
+	if v.Type == bsontype.Null {
+		return 0
+	}
+	return 1
+}
+
+func numberValue(v bson.RawValue) (float64, bool) {
+	switch v.Type {
+	case bsontype.Double:
+		return v.Double(), true
+	case bsontype.Int32:
+		return float64(v.Int32()), true
+	case bsontype.Int64:
+		return float64(v.Int64()), true
+	}
+	return 0, false
+}
+
+// matchesCondition evaluates cond against stock the way MongoDB evaluates
+// the filter Store builds from it.
+func matchesCondition(stock *Stock, cond Condition) bool {
+	switch c := cond.(type) {
+	case And:
+		for _, term := range c {
+			if !matchesCondition(stock, term) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, term := range c {
+			if matchesCondition(stock, term) {
+				return true
+			}
+		}
+		return false
+	case Not:
+		return !matchesCondition(stock, c.Condition)
+	case Comparison:
+		n, comparable := compareField(stock, c.Field, c.Value)
+		switch c.Op {
+		case OpEq:
+			return comparable && n == 0
+		case OpNe:
+			return !comparable || n != 0
+		case OpLt:
+			return comparable && n < 0
+		case OpLte:
+			return comparable && n <= 0
+		case OpGt:
+			return comparable && n > 0
+		case OpGte:
+			return comparable && n >= 0
+		}
+	}
+	return false
+}
+
+// compareField compares the field of stock with value. Like MongoDB it only
+// compares values of the same kind, so a null ratio is not comparable with
+// any number.
+func compareField(stock *Stock, field string, value interface{}) (int, bool) {
+	f, ok := stockFieldValue(stock, field)
+	if !ok {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case float64:
+		var n float64
+		switch f.Kind() {
+		case reflect.Float64:
+			n = f.Float()
+		case reflect.Int64:
+			n = float64(f.Int())
+		default:
+			return 0, false
+		}
+		if r, ok := f.Interface().(Ratio); (ok && !r.valid()) || math.IsNaN(n) {
+			return 0, false
+		}
+		switch {
+		case n < v:
+			return -1, true
+		case n > v:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		if f.Kind() != reflect.String {
+			return 0, false
+		}
+		return strings.Compare(f.String(), v), true
+	case time.Time:
+		t, ok := f.Interface().(time.Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case t.Before(v):
+			return -1, true
+		case t.After(v):
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
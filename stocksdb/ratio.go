@@ -0,0 +1,53 @@
+package stocksdb
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Ratio is a value computed from the statements, like debt to equity. It
+// is NaN when it cannot be computed, for example because the denominator
+// is 0. Such a ratio is stored as null, so it matches no screen and sorts
+// like a missing value, and is encoded as JSON null.
+type Ratio float64
+
+func (r Ratio) valid() bool {
+	return !math.IsNaN(float64(r)) && !math.IsInf(float64(r), 0)
+}
+
+func (r Ratio) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if !r.valid() {
+		return bsontype.Null, nil, nil
+	}
+	return bsontype.Double, bsoncore.AppendDouble(nil, float64(r)), nil
+}
+
+func (r *Ratio) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	v := bsoncore.Value{Type: t, Data: data}
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*r = Ratio(math.NaN())
+	case bsontype.Double:
+		*r = Ratio(v.Double())
+	case bsontype.Int32:
+		*r = Ratio(v.Int32())
+	case bsontype.Int64:
+		*r = Ratio(v.Int64())
+	default:
+		return errors.New("cannot decode " + t.String() + " into a ratio")
+	}
+	return nil
+}
+
+// MarshalJSON also covers documents stored with NaN before ratios were
+// stored as null.
+func (r Ratio) MarshalJSON() ([]byte, error) {
+	if !r.valid() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(r))
+}
@@ -0,0 +1,82 @@
+package stocksdb
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"stocks/marketdata"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func zeroEquity(ticker string) *marketdata.Fundamentals {
+	f := &marketdata.Fundamentals{Symbol: ticker}
+	f.BalanceQuarterly = []marketdata.BalanceSheet{{TotalLiab: marketdata.Int{Value: 500}}}
+	return f
+}
+
+func TestRatioWithoutValueIsStoredAsNull(t *testing.T) {
+	m := NewMemoryRepository()
+	stock, err := m.NewStock(context.Background(), zeroEquity("ZERO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := bson.Marshal(stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"debttoequity", "roic", "enterprisetoebit"} {
+		if v := bson.Raw(doc).Lookup(field); v.Type != bsontype.Null {
+			t.Errorf("%s stored as %s, want null", field, v.Type)
+		}
+	}
+
+	b, err := json.Marshal(stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := fields["debttoequity"]; !ok || v != nil {
+		t.Errorf("debttoequity encoded as %v, want null", v)
+	}
+}
+
+func TestRatioStoredAsNaNEncodesAsNull(t *testing.T) {
+	doc, err := bson.Marshal(bson.M{"ticker": "OLD", "debttoequity": math.NaN(), "roic": math.Inf(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stock Stock
+	if err := bson.Unmarshal(doc, &stock); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := json.Marshal(stock); err != nil {
+		t.Errorf("encoding a stock stored with NaN: %v", err)
+	}
+}
+
+func TestListStocksSortsNullFirst(t *testing.T) {
+	m := NewMemoryRepository()
+	f := zeroEquity("ONE")
+	f.BalanceQuarterly[0].TotalStockholderEquity = marketdata.Int{Value: 500}
+	for _, f := range []*marketdata.Fundamentals{f, zeroEquity("ZERO")} {
+		if _, err := m.NewStock(context.Background(), f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stocks, next, err := m.ListStocks(context.Background(), StockQuery{SortField: "debttoequity", Limit: 1})
+	if err != nil || len(stocks) != 1 || stocks[0].Ticker != "ZERO" {
+		t.Fatalf("first page = %v, %v", stocks, err)
+	}
+	stocks, _, err = m.ListStocks(context.Background(), StockQuery{SortField: "debttoequity", Limit: 1, Cursor: next})
+	if err != nil || len(stocks) != 1 || stocks[0].Ticker != "ONE" || stocks[0].DebtToEquity != 1 {
+		t.Fatalf("second page = %v, %v", stocks, err)
+	}
+}
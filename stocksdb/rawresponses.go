@@ -0,0 +1,124 @@
+package stocksdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RawResponse struct {
+	Ticker    string    `bson:"ticker"`
+	Provider  string    `bson:"provider"`
+	FetchedAt time.Time `bson:"fetchedat"`
+	Encoding  string    `bson:"encoding"`
+	Body      []byte    `bson:"body"`
+}
+
+var rawResponsesColl = "raw_responses"
+
+// SaveRawResponse stores the gzip compressed provider response body.
+func (s *Store) SaveRawResponse(ctx context.Context, ticker, provider string, fetchedAt time.Time, body []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if err := s.ensureRawResponsesIndex(ctx); err != nil {
+		return err
+	}
+
+	raw := RawResponse{
+		Ticker:    ticker,
+		Provider:  provider,
+		FetchedAt: fetchedAt,
+		Encoding:  "gzip",
+		Body:      buf.Bytes(),
+	}
+
+	collection := s.db.Collection(rawResponsesColl)
+	_, err := collection.InsertOne(ctx, raw)
+	return err
+}
+
+// GetRawResponse returns the newest stored response of ticker fetched at or
+// before the given time, with its body decompressed. A zero time selects the
+// newest response. It returns nil when nothing is stored.
+func (s *Store) GetRawResponse(ctx context.Context, ticker string, at time.Time) (*RawResponse, error) {
+	filter := bson.M{"ticker": ticker}
+	if !at.IsZero() {
+		filter["fetchedat"] = bson.M{"$lte": at}
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "fetchedat", Value: -1}})
+
+	var raw RawResponse
+	collection := s.db.Collection(rawResponsesColl)
+	err := collection.FindOne(ctx, filter, opts).Decode(&raw)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if raw.Encoding == "gzip" {
+		zr, err := gzip.NewReader(bytes.NewReader(raw.Body))
+		if err != nil {
+			return nil, err
+		}
+		raw.Body, err = ioutil.ReadAll(zr)
+		if err != nil {
+			return nil, err
+		}
+		raw.Encoding = ""
+	}
+
+	return &raw, nil
+}
+
+func (s *Store) RawResponseTickers(ctx context.Context) ([]string, error) {
+	collection := s.db.Collection(rawResponsesColl)
+	values, err := collection.Distinct(ctx, "ticker", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make([]string, 0, len(values))
+	for _, v := range values {
+		if t, ok := v.(string); ok {
+			tickers = append(tickers, t)
+		}
+	}
+	sort.Strings(tickers)
+	return tickers, nil
+}
+
+func (s *Store) ensureRawResponsesIndex(ctx context.Context) error {
+	s.setupMu.Lock()
+	defer s.setupMu.Unlock()
+
+	if s.rawResponsesIndexReady {
+		return nil
+	}
+
+	collection := s.db.Collection(rawResponsesColl)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "ticker", Value: 1}, {Key: "fetchedat", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	s.rawResponsesIndexReady = true
+	return nil
+}
@@ -0,0 +1,57 @@
+package stocksdb
+
+import (
+	"context"
+	"stocks/marketdata"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockRepository is the storage used by the handlers and the job workers.
+// Store implements it on MongoDB and MemoryRepository in process memory.
+type StockRepository interface {
+	Ping(ctx context.Context) error
+
+	FindStock(ctx context.Context, ticker string) (bool, error)
+	GetStock(ctx context.Context, ticker string) (*Stock, error)
+	NewStock(ctx context.Context, f *marketdata.Fundamentals) (*Stock, error)
+	UpdateStock(ctx context.Context, f *marketdata.Fundamentals, ticker string) (*Stock, error)
+	ListStocks(ctx context.Context, q StockQuery) ([]Stock, string, error)
+	ScreenStocks(ctx context.Context, cond Condition, limit int64) ([]Stock, error)
+
+	ArchiveStock(ctx context.Context, ticker, reason string) (bool, error)
+	RestoreStock(ctx context.Context, ticker string) (bool, error)
+	GetArchivedStock(ctx context.Context, ticker string) (*ArchivedStock, error)
+
+	GetKey(ctx context.Context, name string) (*Key, error)
+	AcquireKey(ctx context.Context, name string) (*Key, error)
+	MarkKeyExhausted(ctx context.Context, id string) error
+	InsertKey(ctx context.Context, key *Key) error
+	ListKeys(ctx context.Context, name string) ([]Key, error)
+	SetKeyDisabled(ctx context.Context, id string, disabled bool) (bool, error)
+	DeleteKey(ctx context.Context, id string) (bool, error)
+
+	SaveRawResponse(ctx context.Context, ticker, provider string, fetchedAt time.Time, body []byte) error
+	GetRawResponse(ctx context.Context, ticker string, at time.Time) (*RawResponse, error)
+	RawResponseTickers(ctx context.Context) ([]string, error)
+
+	SaveSnapshot(ctx context.Context, stock *Stock) error
+	GetHistory(ctx context.Context, ticker, field string, from, to time.Time) ([]HistoryPoint, error)
+
+	NewJob(ctx context.Context, ticker, provider string) (*Job, error)
+	GetJob(ctx context.Context, id string) (*Job, error)
+	ClaimJob(ctx context.Context) (*Job, error)
+	FinishJob(ctx context.Context, id primitive.ObjectID, state, result, message, jobErr string) error
+	RetryJob(ctx context.Context, id primitive.ObjectID, jobErr string, runAfter time.Time) error
+	ReleaseJob(ctx context.Context, id primitive.ObjectID) error
+	RequeueStaleJobs(ctx context.Context, maxAge time.Duration) (int64, error)
+
+	SaveScreen(ctx context.Context, screen *Screen) error
+	GetScreen(ctx context.Context, name string) (*Screen, error)
+	ListScreens(ctx context.Context) ([]Screen, error)
+	DeleteScreen(ctx context.Context, name string) (bool, error)
+}
+
+var _ StockRepository = (*Store)(nil)
+var _ StockRepository = (*MemoryRepository)(nil)
@@ -0,0 +1,121 @@
+package stocksdb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Screen struct {
+	Name       string    `json:"name" bson:"name"`
+	Expression string    `json:"expression" bson:"expression"`
+	Columns    []string  `json:"columns" bson:"columns"`
+	CreatedAt  time.Time `json:"createdAt" bson:"createdat"`
+	UpdatedAt  time.Time `json:"updatedAt" bson:"updatedat"`
+}
+
+var screensColl = "screens"
+
+var mongoOps = map[CompareOp]string{
+	OpEq:  "$eq",
+	OpNe:  "$ne",
+	OpLt:  "$lt",
+	OpLte: "$lte",
+	OpGt:  "$gt",
+	OpGte: "$gte",
+}
+
+// screenFilter translates a screen condition into a MongoDB filter. Not
+// becomes $nor, which unlike $not also matches documents without the field.
+func screenFilter(cond Condition) bson.M {
+	switch c := cond.(type) {
+	case And:
+		return bson.M{"$and": screenFilters(c)}
+	case Or:
+		return bson.M{"$or": screenFilters(c)}
+	case Not:
+		return bson.M{"$nor": bson.A{screenFilter(c.Condition)}}
+	case Comparison:
+		return bson.M{c.Field: bson.M{mongoOps[c.Op]: c.Value}}
+	}
+	return nil
+}
+
+func screenFilters(terms []Condition) bson.A {
+	filters := make(bson.A, 0, len(terms))
+	for _, term := range terms {
+		filters = append(filters, screenFilter(term))
+	}
+	return filters
+}
+
+func (s *Store) ScreenStocks(ctx context.Context, cond Condition, limit int64) ([]Stock, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "ticker", Value: 1}}).
+		SetLimit(limit)
+
+	collection := s.db.Collection(stocksColl)
+	cur, err := collection.Find(ctx, screenFilter(cond), opts)
+	if err != nil {
+		return nil, err
+	}
+	var stocks []Stock
+	if err := cur.All(ctx, &stocks); err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+func (s *Store) SaveScreen(ctx context.Context, screen *Screen) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"expression": screen.Expression,
+			"columns":    screen.Columns,
+			"updatedat":  now,
+		},
+		"$setOnInsert": bson.M{"createdat": now},
+	}
+
+	collection := s.db.Collection(screensColl)
+	_, err := collection.UpdateOne(ctx, bson.M{"name": screen.Name}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *Store) GetScreen(ctx context.Context, name string) (*Screen, error) {
+	var screen Screen
+	collection := s.db.Collection(screensColl)
+	err := collection.FindOne(ctx, bson.M{"name": name}).Decode(&screen)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &screen, nil
+}
+
+func (s *Store) ListScreens(ctx context.Context) ([]Screen, error) {
+	collection := s.db.Collection(screensColl)
+	cur, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	screens := []Screen{}
+	if err := cur.All(ctx, &screens); err != nil {
+		return nil, err
+	}
+	return screens, nil
+}
+
+func (s *Store) DeleteScreen(ctx context.Context, name string) (bool, error) {
+	collection := s.db.Collection(screensColl)
+	res, err := collection.DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
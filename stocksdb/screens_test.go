@@ -0,0 +1,79 @@
+package stocksdb
+
+import (
+	"context"
+	"reflect"
+	"stocks/marketdata"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestScreenStocksEvaluatesConditions(t *testing.T) {
+	m := NewMemoryRepository()
+	one := zeroEquity("ONE")
+	one.BalanceQuarterly[0].TotalStockholderEquity = marketdata.Int{Value: 500}
+	one.Profile.Sector = "Technology"
+	one.Profile.Employees = 150
+	for _, f := range []*marketdata.Fundamentals{one, zeroEquity("ZERO")} {
+		if _, err := m.NewStock(context.Background(), f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		cond Condition
+		want string
+	}{
+		{"number", Comparison{Field: "debttoequity", Op: OpLte, Value: 1.0}, "ONE"},
+		{"null only matches not equal", Comparison{Field: "debttoequity", Op: OpNe, Value: 1.0}, "ZERO"},
+		{"integer field", Comparison{Field: "employeeno", Op: OpGt, Value: 100.0}, "ONE"},
+		{"string", Comparison{Field: "sector", Op: OpEq, Value: "Technology"}, "ONE"},
+		{"not", Not{Comparison{Field: "debttoequity", Op: OpGte, Value: 0.0}}, "ZERO"},
+		{"and", And{
+			Comparison{Field: "sector", Op: OpEq, Value: "Technology"},
+			Comparison{Field: "employeeno", Op: OpLt, Value: 100.0},
+		}, ""},
+		{"or", Or{
+			Comparison{Field: "sector", Op: OpEq, Value: "Technology"},
+			Comparison{Field: "ticker", Op: OpEq, Value: "ZERO"},
+		}, "ONE ZERO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stocks, err := m.ScreenStocks(context.Background(), tt.cond, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var tickers []string
+			for _, s := range stocks {
+				tickers = append(tickers, s.Ticker)
+			}
+			if got := strings.Join(tickers, " "); got != tt.want {
+				t.Errorf("matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenFilter(t *testing.T) {
+	cond := Or{
+		And{
+			Comparison{Field: "beta", Op: OpLt, Value: 1.5},
+			Not{Comparison{Field: "sector", Op: OpEq, Value: "Energy"}},
+		},
+		Comparison{Field: "roic", Op: OpGte, Value: 0.2},
+	}
+	want := bson.M{"$or": bson.A{
+		bson.M{"$and": bson.A{
+			bson.M{"beta": bson.M{"$lt": 1.5}},
+			bson.M{"$nor": bson.A{bson.M{"sector": bson.M{"$eq": "Energy"}}}},
+		}},
+		bson.M{"roic": bson.M{"$gte": 0.2}},
+	}}
+	if got := screenFilter(cond); !reflect.DeepEqual(got, want) {
+		t.Errorf("screenFilter = %v, want %v", got, want)
+	}
+}
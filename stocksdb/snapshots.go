@@ -0,0 +1,130 @@
+package stocksdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type HistoryPoint struct {
+	Time  time.Time   `json:"ts"`
+	Value interface{} `json:"value"`
+}
+
+type historyDoc struct {
+	Time  time.Time     `bson:"ts"`
+	Value bson.RawValue `bson:"value"`
+}
+
+var snapshotsColl = "stock_snapshots"
+
+const mongoNamespaceExists = 48
+
+// HistoryField reports whether the field is recorded in the snapshots.
+func HistoryField(name string) bool {
+	kind, ok := StockField(name)
+	return (ok && kind == FieldNumber) || name == "recommtrend"
+}
+
+// SaveSnapshot writes the scalar metrics of the stock into the
+// stock_snapshots time-series collection.
+func (s *Store) SaveSnapshot(ctx context.Context, stock *Stock) error {
+	if err := s.ensureSnapshotsColl(ctx); err != nil {
+		return err
+	}
+
+	raw, err := bson.Marshal(stock)
+	if err != nil {
+		return err
+	}
+	elems, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return err
+	}
+
+	snapshot := bson.D{{Key: "ticker", Value: stock.Ticker}, {Key: "ts", Value: stock.LastUpdated}}
+	for _, e := range elems {
+		if HistoryField(e.Key()) {
+			snapshot = append(snapshot, bson.E{Key: e.Key(), Value: e.Value()})
+		}
+	}
+
+	collection := s.db.Collection(snapshotsColl)
+	_, err = collection.InsertOne(ctx, snapshot)
+	return err
+}
+
+// GetHistory returns the values of field recorded for ticker between from
+// and to, oldest first. Zero times leave that side of the range open.
+func (s *Store) GetHistory(ctx context.Context, ticker, field string, from, to time.Time) ([]HistoryPoint, error) {
+	if !HistoryField(field) {
+		return nil, errors.New("field " + field + " is not recorded in snapshots")
+	}
+
+	filter := bson.M{"ticker": ticker}
+	tsRange := bson.M{}
+	if !from.IsZero() {
+		tsRange["$gte"] = from
+	}
+	if !to.IsZero() {
+		tsRange["$lt"] = to
+	}
+	if len(tsRange) > 0 {
+		filter["ts"] = tsRange
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "ts", Value: 1}}).
+		SetProjection(bson.M{"_id": 0, "ts": 1, "value": "$" + field})
+
+	collection := s.db.Collection(snapshotsColl)
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []historyDoc
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	points := make([]HistoryPoint, 0, len(docs))
+	for _, d := range docs {
+		p := HistoryPoint{Time: d.Time}
+		if d.Value.Type == bsontype.EmbeddedDocument {
+			var m bson.M
+			err = d.Value.Unmarshal(&m)
+			p.Value = m
+		} else if d.Value.Type != 0 {
+			err = d.Value.Unmarshal(&p.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func (s *Store) ensureSnapshotsColl(ctx context.Context) error {
+	s.setupMu.Lock()
+	defer s.setupMu.Unlock()
+
+	if s.snapshotsCollReady {
+		return nil
+	}
+
+	ts := options.TimeSeries().SetTimeField("ts").SetMetaField("ticker").SetGranularity("hours")
+	err := s.db.CreateCollection(ctx, snapshotsColl, options.CreateCollection().SetTimeSeriesOptions(ts))
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.HasErrorCode(mongoNamespaceExists)) {
+		return err
+	}
+
+	s.snapshotsCollReady = true
+	return nil
+}
@@ -2,754 +2,654 @@ package stocksdb
 
 import (
 	"context"
-	"log"
+	"errors"
 	"math"
+	"net"
 	"net/http"
-	"os"
-	"stocks/yahoodata"
+	"stocks/breaker"
+	"stocks/marketdata"
 	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Stock struct {
-	ID                          primitive.ObjectID `bson:"_id,omitempty"`
-	Name                        string             `bson:"name"`
-	Ticker                      string             `bson:"ticker"`
-	Beta                        float64            `bson:"beta"`
-	Industry                    string             `bson:"industry"`
-	Sector                      string             `bson:"sector"`
-	Address                     string             `bson:"address"`
-	City                        string             `bson:"city"`
-	Country                     string             `bson:"country"`
-	EmployeeNo                  int64              `bson:"employeeno"`
-	RecommTrend                 recommTrend        `bson:"recommtrend"`
-	CashFlowH                   []cashFlowH        `bson:"cashflowh"`
-	EnterpriseValue             int64              `bson:"enterprisevalue"`
-	EnterpriseValueNice         string             `bson:"enterprisevaluenice"`
-	ForwardPE                   float64            `bson:"forwardpe"`
-	ForwardPENice               string             `bson:"forwardpenice"`
-	ProfitMargins               float64            `bson:"profitmargins"`
-	ProfitMarginsNice           string             `bson:"profitmarginsnice"`
-	FloatShares                 int64              `bson:"floatshares"`
-	FloatSharesNice             string             `bson:"floatsharesnice"`
-	SharesOutstanding           int64              `bson:"sharesoutstanding"`
-	SharesOutstandingNice       string             `bson:"sharesoutstandingnice"`
-	SharesShort                 int64              `bson:"sharesshort"`
-	SharesShortNice             string             `bson:"sharesshortnice"`
-	HeldPercentInsiders         float64            `bson:"heldpercentinsiders"`
-	HeldPercentInsidersNice     string             `bson:"heldpercentinsidersnice"`
-	HeldPercentInstitutions     float64            `bson:"heldpercentinstitutions"`
-	HeldPercentInstitutionsNice string             `bson:"heldpercentinstitutionsnice"`
-	ShortRatio                  float64            `bson:"shortratio"`
-	ShortRatioNice              string             `bson:"shortrationice"`
-	ShortPercentOfFloat         float64            `bson:"shortpercentoffloat"`
-	ShortPercentOfFloatNice     string             `bson:"shortpercentoffloatnice"`
-	BookValue                   float64            `bson:"bookvalue"`
-	BookValueNice               string             `bson:"bookvaluenice"`
-	PriceToBook                 float64            `bson:"pricetobook"`
-	PriceToBookNice             string             `bson:"pricetobooknice"`
-	LastFiscalYearEnd           string             `bson:"lastfiscalyearend"`
-	MostRecentQuarter           string             `bson:"mostrecentquarter"`
-	NetIncomeToCommon           int64              `bson:"netincometocommon"`
-	NetIncomeToCommonNice       string             `bson:"netincometocommonnice"`
-	TrailingEps                 float64            `bson:"trailingeps"`
-	TrailingEpsNice             string             `bson:"trailingepsnice"`
-	ForwardEps                  float64            `bson:"forwardeps"`
-	ForwardEpsNice              string             `bson:"forwardepsnice"`
-	PegRatio                    float64            `bson:"pegratio"`
-	PegRatioNice                string             `bson:"pegrationice"`
-	LastSplitFactor             string             `bson:"lastsplitfactor"`
-	LastSplitDate               string             `bson:"lastsplitdate"`
-	EnterpriseToRevenue         float64            `bson:"enterprisetorevenue"`
-	EnterpriseToRevenueNice     string             `bson:"enterprisetorevenuenice"`
-	EnterpriseToEbitda          float64            `bson:"enterprisetoebitda"`
-	EnterpriseToEbitdaNice      string             `bson:"enterprisetoebitdanice"`
-	WeekChange52                float64            `bson:"weekchange52"`
-	WeekChange52Nice            string             `bson:"weekchange52nice"`
-	Exchange                    string             `bson:"exchange"`
-	IncomeH                     []incomeH          `bson:"incomeh"`
-	Currency                    string             `bson:"currency"`
-	ExDividendDate              string             `bson:"exdividenddate"`
-	DividendRate                float64            `bson:"dividendrate"`
-	DividendRateNice            string             `bson:"dividendratenice"`
-	DividendYield               float64            `bson:"dividendyield"`
-	DividendYieldNice           string             `bson:"dividendyieldnice"`
-	PayoutRatio                 float64            `bson:"payoutratio"`
-	PayoutRatioNice             string             `bson:"payoutrationice"`
-	TrailingPE                  float64            `bson:"trailingpe"`
-	TrailingPENice              string             `bson:"trailingpenice"`
-	MarketCap                   int64              `bson:"marketcap"`
-	MarketCapNice               string             `bson:"marketcapnice"`
-	EarningsNext                earningsN          `bson:"earningsnext"`
-	BalanceH                    []balanceH         `bson:"balanceh"`
-	Growth5y                    float64            `bson:"growth5y"`
-	Growth5yNice                string             `bson:"growth5ynice"`
-	BalanceHQ                   []balanceH         `bson:"balancehq"`
-	IncomeHQ                    []incomeH          `bson:"incomehq"`
-	CashFlowHQ                  []cashFlowH        `bson:"cashflowhq"`
-	Price                       float64            `bson:"price"`
-	TargetHighPrice             float64            `bson:"targethighprice"`
-	TargetLowPrice              float64            `bson:"targetlowprice"`
-	TargetMedianPrice           float64            `bson:"targetmedianprice"`
-	RecommendationKey           string             `bson:"recommendationkey"`
-	TotalCash                   int64              `bson:"totalcash"`
-	TotalCashNice               string             `bson:"totalcashnice"`
-	TotalCashPerShare           float64            `bson:"totalcashpershare"`
-	TotalCashPerShareNice       string             `bson:"totalcashpersharenice"`
-	Ebitda                      int64              `bson:"ebitda"`
-	EbitdaNice                  string             `bson:"ebitdanice"`
-	TotalDebt                   int64              `bson:"totaldebt"`
-	TotalDebtNice               string             `bson:"totaldebtnice"`
-	QuickRatio                  float64            `bson:"quickratio"`
-	QuickRatioNice              string             `bson:"quickrationice"`
-	CurrentRatio                float64            `bson:"currentratio"`
-	CurrentRatioNice            string             `bson:"currentrationice"`
-	TotalRevenue                int64              `bson:"totalrevenue"`
-	TotalRevenueNice            string             `bson:"totalrevenuenice"`
-	DebtToEquity                float64            `bson:"debttoequity"`
-	RevenuePerShare             float64            `bson:"revenuepershare"`
-	RevenuePerShareNice         string             `bson:"revenuepersharenice"`
-	ReturnOnAssets              float64            `bson:"returnonassets"`
-	ReturnOnAssetsNice          string             `bson:"returnonassetsnice"`
-	ReturnOnEquity              float64            `bson:"returnonequity"`
-	ReturnOnEquityNice          string             `bson:"returnonequitynice"`
-	GrossProfits                int64              `bson:"grossprofits"`
-	GrossProfitsNice            string             `bson:"grossprofitsnice"`
-	FreeCashflow                int64              `bson:"freecashflow"`
-	FreeCashflowNice            string             `bson:"freecashflownice"`
-	OperatingCashflow           int64              `bson:"operatingcashflow"`
-	OperatingCashflowNice       string             `bson:"operatingcashflownice"`
-	GrossMargins                float64            `bson:"grossmargins"`
-	GrossMarginsNice            string             `bson:"grossmarginsnice"`
-	EbitdaMargins               float64            `bson:"ebitdamargins"`
-	EbitdaMarginsNice           string             `bson:"ebitdamarginsnice"`
-	OperatingMargins            float64            `bson:"operatingmargins"`
-	OperatingMarginsNice        string             `bson:"operatingmarginsnice"`
-	ROIC                        float64            `bson:"roic"`
-	WorkingCapital              int64              `bson:"workingcapital"`
-	EnterpriseToEbit            float64            `bson:"enterprisetoebit"`
-	LastUpdated                 time.Time          `bson:"lastupdated"`
+	ID                          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name                        string             `json:"name" bson:"name"`
+	Ticker                      string             `json:"ticker" bson:"ticker"`
+	Beta                        float64            `json:"beta" bson:"beta"`
+	Industry                    string             `json:"industry" bson:"industry"`
+	Sector                      string             `json:"sector" bson:"sector"`
+	Address                     string             `json:"address" bson:"address"`
+	City                        string             `json:"city" bson:"city"`
+	Country                     string             `json:"country" bson:"country"`
+	EmployeeNo                  int64              `json:"employeeno" bson:"employeeno"`
+	RecommTrend                 recommTrend        `json:"recommtrend" bson:"recommtrend"`
+	CashFlowH                   []cashFlowH        `json:"cashflowh" bson:"cashflowh"`
+	EnterpriseValue             int64              `json:"enterprisevalue" bson:"enterprisevalue"`
+	EnterpriseValueNice         string             `json:"enterprisevaluenice" bson:"enterprisevaluenice"`
+	ForwardPE                   float64            `json:"forwardpe" bson:"forwardpe"`
+	ForwardPENice               string             `json:"forwardpenice" bson:"forwardpenice"`
+	ProfitMargins               float64            `json:"profitmargins" bson:"profitmargins"`
+	ProfitMarginsNice           string             `json:"profitmarginsnice" bson:"profitmarginsnice"`
+	FloatShares                 int64              `json:"floatshares" bson:"floatshares"`
+	FloatSharesNice             string             `json:"floatsharesnice" bson:"floatsharesnice"`
+	SharesOutstanding           int64              `json:"sharesoutstanding" bson:"sharesoutstanding"`
+	SharesOutstandingNice       string             `json:"sharesoutstandingnice" bson:"sharesoutstandingnice"`
+	SharesShort                 int64              `json:"sharesshort" bson:"sharesshort"`
+	SharesShortNice             string             `json:"sharesshortnice" bson:"sharesshortnice"`
+	HeldPercentInsiders         float64            `json:"heldpercentinsiders" bson:"heldpercentinsiders"`
+	HeldPercentInsidersNice     string             `json:"heldpercentinsidersnice" bson:"heldpercentinsidersnice"`
+	HeldPercentInstitutions     float64            `json:"heldpercentinstitutions" bson:"heldpercentinstitutions"`
+	HeldPercentInstitutionsNice string             `json:"heldpercentinstitutionsnice" bson:"heldpercentinstitutionsnice"`
+	ShortRatio                  float64            `json:"shortratio" bson:"shortratio"`
+	ShortRatioNice              string             `json:"shortrationice" bson:"shortrationice"`
+	ShortPercentOfFloat         float64            `json:"shortpercentoffloat" bson:"shortpercentoffloat"`
+	ShortPercentOfFloatNice     string             `json:"shortpercentoffloatnice" bson:"shortpercentoffloatnice"`
+	BookValue                   float64            `json:"bookvalue" bson:"bookvalue"`
+	BookValueNice               string             `json:"bookvaluenice" bson:"bookvaluenice"`
+	PriceToBook                 float64            `json:"pricetobook" bson:"pricetobook"`
+	PriceToBookNice             string             `json:"pricetobooknice" bson:"pricetobooknice"`
+	LastFiscalYearEnd           string             `json:"lastfiscalyearend" bson:"lastfiscalyearend"`
+	MostRecentQuarter           string             `json:"mostrecentquarter" bson:"mostrecentquarter"`
+	NetIncomeToCommon           int64              `json:"netincometocommon" bson:"netincometocommon"`
+	NetIncomeToCommonNice       string             `json:"netincometocommonnice" bson:"netincometocommonnice"`
+	TrailingEps                 float64            `json:"trailingeps" bson:"trailingeps"`
+	TrailingEpsNice             string             `json:"trailingepsnice" bson:"trailingepsnice"`
+	ForwardEps                  float64            `json:"forwardeps" bson:"forwardeps"`
+	ForwardEpsNice              string             `json:"forwardepsnice" bson:"forwardepsnice"`
+	PegRatio                    float64            `json:"pegratio" bson:"pegratio"`
+	PegRatioNice                string             `json:"pegrationice" bson:"pegrationice"`
+	LastSplitFactor             string             `json:"lastsplitfactor" bson:"lastsplitfactor"`
+	LastSplitDate               string             `json:"lastsplitdate" bson:"lastsplitdate"`
+	EnterpriseToRevenue         float64            `json:"enterprisetorevenue" bson:"enterprisetorevenue"`
+	EnterpriseToRevenueNice     string             `json:"enterprisetorevenuenice" bson:"enterprisetorevenuenice"`
+	EnterpriseToEbitda          float64            `json:"enterprisetoebitda" bson:"enterprisetoebitda"`
+	EnterpriseToEbitdaNice      string             `json:"enterprisetoebitdanice" bson:"enterprisetoebitdanice"`
+	WeekChange52                float64            `json:"weekchange52" bson:"weekchange52"`
+	WeekChange52Nice            string             `json:"weekchange52nice" bson:"weekchange52nice"`
+	Exchange                    string             `json:"exchange" bson:"exchange"`
+	IncomeH                     []incomeH          `json:"incomeh" bson:"incomeh"`
+	Currency                    string             `json:"currency" bson:"currency"`
+	ExDividendDate              string             `json:"exdividenddate" bson:"exdividenddate"`
+	DividendRate                float64            `json:"dividendrate" bson:"dividendrate"`
+	DividendRateNice            string             `json:"dividendratenice" bson:"dividendratenice"`
+	DividendYield               float64            `json:"dividendyield" bson:"dividendyield"`
+	DividendYieldNice           string             `json:"dividendyieldnice" bson:"dividendyieldnice"`
+	PayoutRatio                 float64            `json:"payoutratio" bson:"payoutratio"`
+	PayoutRatioNice             string             `json:"payoutrationice" bson:"payoutrationice"`
+	TrailingPE                  float64            `json:"trailingpe" bson:"trailingpe"`
+	TrailingPENice              string             `json:"trailingpenice" bson:"trailingpenice"`
+	MarketCap                   int64              `json:"marketcap" bson:"marketcap"`
+	MarketCapNice               string             `json:"marketcapnice" bson:"marketcapnice"`
+	EarningsNext                earningsN          `json:"earningsnext" bson:"earningsnext"`
+	BalanceH                    []balanceH         `json:"balanceh" bson:"balanceh"`
+	Growth5y                    float64            `json:"growth5y" bson:"growth5y"`
+	Growth5yNice                string             `json:"growth5ynice" bson:"growth5ynice"`
+	BalanceHQ                   []balanceH         `json:"balancehq" bson:"balancehq"`
+	IncomeHQ                    []incomeH          `json:"incomehq" bson:"incomehq"`
+	CashFlowHQ                  []cashFlowH        `json:"cashflowhq" bson:"cashflowhq"`
+	Price                       float64            `json:"price" bson:"price"`
+	TargetHighPrice             float64            `json:"targethighprice" bson:"targethighprice"`
+	TargetLowPrice              float64            `json:"targetlowprice" bson:"targetlowprice"`
+	TargetMedianPrice           float64            `json:"targetmedianprice" bson:"targetmedianprice"`
+	RecommendationKey           string             `json:"recommendationkey" bson:"recommendationkey"`
+	TotalCash                   int64              `json:"totalcash" bson:"totalcash"`
+	TotalCashNice               string             `json:"totalcashnice" bson:"totalcashnice"`
+	TotalCashPerShare           float64            `json:"totalcashpershare" bson:"totalcashpershare"`
+	TotalCashPerShareNice       string             `json:"totalcashpersharenice" bson:"totalcashpersharenice"`
+	Ebitda                      int64              `json:"ebitda" bson:"ebitda"`
+	EbitdaNice                  string             `json:"ebitdanice" bson:"ebitdanice"`
+	TotalDebt                   int64              `json:"totaldebt" bson:"totaldebt"`
+	TotalDebtNice               string             `json:"totaldebtnice" bson:"totaldebtnice"`
+	QuickRatio                  float64            `json:"quickratio" bson:"quickratio"`
+	QuickRatioNice              string             `json:"quickrationice" bson:"quickrationice"`
+	CurrentRatio                float64            `json:"currentratio" bson:"currentratio"`
+	CurrentRatioNice            string             `json:"currentrationice" bson:"currentrationice"`
+	TotalRevenue                int64              `json:"totalrevenue" bson:"totalrevenue"`
+	TotalRevenueNice            string             `json:"totalrevenuenice" bson:"totalrevenuenice"`
+	DebtToEquity                Ratio              `json:"debttoequity" bson:"debttoequity"`
+	RevenuePerShare             float64            `json:"revenuepershare" bson:"revenuepershare"`
+	RevenuePerShareNice         string             `json:"revenuepersharenice" bson:"revenuepersharenice"`
+	ReturnOnAssets              float64            `json:"returnonassets" bson:"returnonassets"`
+	ReturnOnAssetsNice          string             `json:"returnonassetsnice" bson:"returnonassetsnice"`
+	ReturnOnEquity              float64            `json:"returnonequity" bson:"returnonequity"`
+	ReturnOnEquityNice          string             `json:"returnonequitynice" bson:"returnonequitynice"`
+	GrossProfits                int64              `json:"grossprofits" bson:"grossprofits"`
+	GrossProfitsNice            string             `json:"grossprofitsnice" bson:"grossprofitsnice"`
+	FreeCashflow                int64              `json:"freecashflow" bson:"freecashflow"`
+	FreeCashflowNice            string             `json:"freecashflownice" bson:"freecashflownice"`
+	OperatingCashflow           int64              `json:"operatingcashflow" bson:"operatingcashflow"`
+	OperatingCashflowNice       string             `json:"operatingcashflownice" bson:"operatingcashflownice"`
+	GrossMargins                float64            `json:"grossmargins" bson:"grossmargins"`
+	GrossMarginsNice            string             `json:"grossmarginsnice" bson:"grossmarginsnice"`
+	EbitdaMargins               float64            `json:"ebitdamargins" bson:"ebitdamargins"`
+	EbitdaMarginsNice           string             `json:"ebitdamarginsnice" bson:"ebitdamarginsnice"`
+	OperatingMargins            float64            `json:"operatingmargins" bson:"operatingmargins"`
+	OperatingMarginsNice        string             `json:"operatingmarginsnice" bson:"operatingmarginsnice"`
+	ROIC                        Ratio              `json:"roic" bson:"roic"`
+	WorkingCapital              int64              `json:"workingcapital" bson:"workingcapital"`
+	EnterpriseToEbit            Ratio              `json:"enterprisetoebit" bson:"enterprisetoebit"`
+	LastUpdated                 time.Time          `json:"lastupdated" bson:"lastupdated"`
 }
 type recommTrend struct {
-	StrongBuy  int64 `bson:"strongbuy"`
-	Buy        int64 `bson:"buy"`
-	Hold       int64 `bson:"hold"`
-	Sell       int64 `bson:"sell"`
-	StrongSell int64 `bson:"strongsell"`
+	StrongBuy  int64 `json:"strongbuy" bson:"strongbuy"`
+	Buy        int64 `json:"buy" bson:"buy"`
+	Hold       int64 `json:"hold" bson:"hold"`
+	Sell       int64 `json:"sell" bson:"sell"`
+	StrongSell int64 `json:"strongsell" bson:"strongsell"`
 }
 type cashFlowH struct {
-	CapEx                        int64  `bson:"capex"`
-	CapExNice                    string `bson:"capexnice"`
-	ChangeCash                   int64  `bson:"changecash"`
-	ChangeCashNice               string `bson:"changecashnice"`
-	ChangeAccountReceivables     int64  `bson:"changeaccountreceivables"`
-	ChangeAccountReceivablesNice string `bson:"changeaccountreceivablesnice"`
-	ChangeInventory              int64  `bson:"changeinventory"`
-	ChangeInventoryNice          string `bson:"changeinventorynice"`
-	ChangeLiabilities            int64  `bson:"changeliabilities"`
-	ChangeLiabilitiesNice        string `bson:"changeliabilitiesnice"`
-	ChangeNetIncome              int64  `bson:"changenetincome"`
-	ChangeNetIncomeNice          string `bson:"changenetincomenice"`
-	Depreciation                 int64  `bson:"depreciation"`
-	DepreciationNice             string `bson:"depreciationnice"`
-	EffectExchangeRate           int64  `bson:"effectexchangerate"`
-	EffectExchangeRateNice       string `bson:"effectexchangeratenice"`
-	EndDate                      string `bson:"enddate"`
-	EndDateY                     string `bson:"enddatey"`
-	Investments                  int64  `bson:"investments"`
-	InvestmentsNice              string `bson:"investmentsnice"`
-	NetBorrowings                int64  `bson:"netborrowings"`
-	NetBorrowingsNice            string `bson:"netborrowingsnice"`
-	NetIncome                    int64  `bson:"netincome"`
-	NetIncomeNice                string `bson:"netincomenice"`
-	OtherCashFinancing           int64  `bson:"othercashfinancing"`
-	OtherCashFinancingNice       string `bson:"othercashfinancingnice"`
-	OtherCashInvesting           int64  `bson:"othercashinvesting"`
-	OtherCashInvestingNice       string `bson:"othercashinvestingnice"`
-	RepurchaseStock              int64  `bson:"repurchasestock"`
-	RepurchaseStockNice          string `bson:"repurchasestocknice"`
-	TotalCashInvesting           int64  `bson:"totalcashinvesting"`
-	TotalCashInvestingNice       string `bson:"totalcashinvestingnice"`
-	TotalCashFinancing           int64  `bson:"totalcashfinancing"`
-	TotalCashFinancingNice       string `bson:"totalcashfinancingnice"`
-	TotalCashOperating           int64  `bson:"totalcashoperating"`
-	TotalCashOperatingNice       string `bson:"totalcashoperatingnice"`
+	CapEx                        int64  `json:"capex" bson:"capex"`
+	CapExNice                    string `json:"capexnice" bson:"capexnice"`
+	ChangeCash                   int64  `json:"changecash" bson:"changecash"`
+	ChangeCashNice               string `json:"changecashnice" bson:"changecashnice"`
+	ChangeAccountReceivables     int64  `json:"changeaccountreceivables" bson:"changeaccountreceivables"`
+	ChangeAccountReceivablesNice string `json:"changeaccountreceivablesnice" bson:"changeaccountreceivablesnice"`
+	ChangeInventory              int64  `json:"changeinventory" bson:"changeinventory"`
+	ChangeInventoryNice          string `json:"changeinventorynice" bson:"changeinventorynice"`
+	ChangeLiabilities            int64  `json:"changeliabilities" bson:"changeliabilities"`
+	ChangeLiabilitiesNice        string `json:"changeliabilitiesnice" bson:"changeliabilitiesnice"`
+	ChangeNetIncome              int64  `json:"changenetincome" bson:"changenetincome"`
+	ChangeNetIncomeNice          string `json:"changenetincomenice" bson:"changenetincomenice"`
+	Depreciation                 int64  `json:"depreciation" bson:"depreciation"`
+	DepreciationNice             string `json:"depreciationnice" bson:"depreciationnice"`
+	EffectExchangeRate           int64  `json:"effectexchangerate" bson:"effectexchangerate"`
+	EffectExchangeRateNice       string `json:"effectexchangeratenice" bson:"effectexchangeratenice"`
+	EndDate                      string `json:"enddate" bson:"enddate"`
+	EndDateY                     string `json:"enddatey" bson:"enddatey"`
+	Investments                  int64  `json:"investments" bson:"investments"`
+	InvestmentsNice              string `json:"investmentsnice" bson:"investmentsnice"`
+	NetBorrowings                int64  `json:"netborrowings" bson:"netborrowings"`
+	NetBorrowingsNice            string `json:"netborrowingsnice" bson:"netborrowingsnice"`
+	NetIncome                    int64  `json:"netincome" bson:"netincome"`
+	NetIncomeNice                string `json:"netincomenice" bson:"netincomenice"`
+	OtherCashFinancing           int64  `json:"othercashfinancing" bson:"othercashfinancing"`
+	OtherCashFinancingNice       string `json:"othercashfinancingnice" bson:"othercashfinancingnice"`
+	OtherCashInvesting           int64  `json:"othercashinvesting" bson:"othercashinvesting"`
+	OtherCashInvestingNice       string `json:"othercashinvestingnice" bson:"othercashinvestingnice"`
+	RepurchaseStock              int64  `json:"repurchasestock" bson:"repurchasestock"`
+	RepurchaseStockNice          string `json:"repurchasestocknice" bson:"repurchasestocknice"`
+	TotalCashInvesting           int64  `json:"totalcashinvesting" bson:"totalcashinvesting"`
+	TotalCashInvestingNice       string `json:"totalcashinvestingnice" bson:"totalcashinvestingnice"`
+	TotalCashFinancing           int64  `json:"totalcashfinancing" bson:"totalcashfinancing"`
+	TotalCashFinancingNice       string `json:"totalcashfinancingnice" bson:"totalcashfinancingnice"`
+	TotalCashOperating           int64  `json:"totalcashoperating" bson:"totalcashoperating"`
+	TotalCashOperatingNice       string `json:"totalcashoperatingnice" bson:"totalcashoperatingnice"`
 }
 type incomeH struct {
-	TotalRevenue                     int64  `bson:"totalrevenue"`
-	TotalRevenueNice                 string `bson:"totalrevenuenice"`
-	CostOfRevenue                    int64  `bson:"costofrevenue"`
-	CostOfRevenueNice                string `bson:"costofrevenuenice"`
-	GrossProfit                      int64  `bson:"grossprofit"`
-	GrossProfitNice                  string `bson:"grossprofitnice"`
-	ResearchDevelopment              int64  `bson:"researchdevelopment"`
-	ResearchDevelopmentNice          string `bson:"researchdevelopmentnice"`
-	SellingGeneralAdministrative     int64  `bson:"sellinggeneraladministrative"`
-	SellingGeneralAdministrativeNice string `bson:"sellinggeneraladministrativenice"`
-	NonRecurring                     int64  `bson:"nonrecurring"`
-	NonRecurringNice                 string `bson:"nonrecurringnice"`
-	OtherOperatingExpenses           int64  `bson:"otheroperatingexpenses"`
-	OtherOperatingExpensesNice       string `bson:"otheroperatingexpensesnice"`
-	TotalOperatingExpenses           int64  `bson:"totaloperatingexpenses"`
-	TotalOperatingExpensesNice       string `bson:"totaloperatingexpensesnice"`
-	EndDate                          string `bson:"enddate"`
-	EndDateY                         string `bson:"enddatey"`
-	OperatingIncome                  int64  `bson:"operatingincome"`
-	OperatingIncomeNice              string `bson:"operatingincomenice"`
-	TotalOtherIncomeExpenseNet       int64  `bson:"totalotherincomeexpensenet"`
-	TotalOtherIncomeExpenseNetNice   string `bson:"totalotherincomeexpensenetnice"`
-	Ebit                             int64  `bson:"ebit"`
-	EbitNice                         string `bson:"ebitnice"`
-	InterestExpense                  int64  `bson:"interestexpense"`
-	InterestExpenseNice              string `bson:"interestexpensenice"`
-	IncomeBeforeTax                  int64  `bson:"incomebeforetax"`
-	IncomeBeforeTaxNice              string `bson:"incomebeforetaxnice"`
-	IncomeTaxExpense                 int64  `bson:"incometaxexpense"`
-	IncomeTaxExpenseNice             string `bson:"incometaxexpensenice"`
-	MinorityInterest                 int64  `bson:"minorityinterest"`
-	MinorityInterestNice             string `bson:"minorityinterestnice"`
-	NetIncomeFromContinuingOps       int64  `bson:"netincomefromcontinuingops"`
-	NetIncomeFromContinuingOpsNice   string `bson:"netincomefromcontinuingopsnice"`
-	DiscontinuedOperations           int64  `bson:"discontinuedoperations"`
-	DiscontinuedOperationsNice       string `bson:"discontinuedoperationsnice"`
-	ExtraordinaryItems               int64  `bson:"extraordinaryitems"`
-	ExtraordinaryItemsNice           string `bson:"extraordinaryitemsnice"`
-	EffectOfAccountingCharges        int64  `bson:"effectofaccountingcharges"`
-	EffectOfAccountingChargesNice    string `bson:"effectofaccountingchargesnice"`
-	OtherItems                       int64  `bson:"otheritems"`
-	OtherItemsNice                   string `bson:"otheritemsnice"`
-	NetIncome                        int64  `bson:"netincome"`
-	NetIncomeNice                    string `bson:"netincomenice"`
-	NetIncomeCommonShares            int64  `bson:"netincomecommonshares"`
-	NetIncomeCommonSharesNice        string `bson:"netincomecommonsharesnice"`
+	TotalRevenue                     int64  `json:"totalrevenue" bson:"totalrevenue"`
+	TotalRevenueNice                 string `json:"totalrevenuenice" bson:"totalrevenuenice"`
+	CostOfRevenue                    int64  `json:"costofrevenue" bson:"costofrevenue"`
+	CostOfRevenueNice                string `json:"costofrevenuenice" bson:"costofrevenuenice"`
+	GrossProfit                      int64  `json:"grossprofit" bson:"grossprofit"`
+	GrossProfitNice                  string `json:"grossprofitnice" bson:"grossprofitnice"`
+	ResearchDevelopment              int64  `json:"researchdevelopment" bson:"researchdevelopment"`
+	ResearchDevelopmentNice          string `json:"researchdevelopmentnice" bson:"researchdevelopmentnice"`
+	SellingGeneralAdministrative     int64  `json:"sellinggeneraladministrative" bson:"sellinggeneraladministrative"`
+	SellingGeneralAdministrativeNice string `json:"sellinggeneraladministrativenice" bson:"sellinggeneraladministrativenice"`
+	NonRecurring                     int64  `json:"nonrecurring" bson:"nonrecurring"`
+	NonRecurringNice                 string `json:"nonrecurringnice" bson:"nonrecurringnice"`
+	OtherOperatingExpenses           int64  `json:"otheroperatingexpenses" bson:"otheroperatingexpenses"`
+	OtherOperatingExpensesNice       string `json:"otheroperatingexpensesnice" bson:"otheroperatingexpensesnice"`
+	TotalOperatingExpenses           int64  `json:"totaloperatingexpenses" bson:"totaloperatingexpenses"`
+	TotalOperatingExpensesNice       string `json:"totaloperatingexpensesnice" bson:"totaloperatingexpensesnice"`
+	EndDate                          string `json:"enddate" bson:"enddate"`
+	EndDateY                         string `json:"enddatey" bson:"enddatey"`
+	OperatingIncome                  int64  `json:"operatingincome" bson:"operatingincome"`
+	OperatingIncomeNice              string `json:"operatingincomenice" bson:"operatingincomenice"`
+	TotalOtherIncomeExpenseNet       int64  `json:"totalotherincomeexpensenet" bson:"totalotherincomeexpensenet"`
+	TotalOtherIncomeExpenseNetNice   string `json:"totalotherincomeexpensenetnice" bson:"totalotherincomeexpensenetnice"`
+	Ebit                             int64  `json:"ebit" bson:"ebit"`
+	EbitNice                         string `json:"ebitnice" bson:"ebitnice"`
+	InterestExpense                  int64  `json:"interestexpense" bson:"interestexpense"`
+	InterestExpenseNice              string `json:"interestexpensenice" bson:"interestexpensenice"`
+	IncomeBeforeTax                  int64  `json:"incomebeforetax" bson:"incomebeforetax"`
+	IncomeBeforeTaxNice              string `json:"incomebeforetaxnice" bson:"incomebeforetaxnice"`
+	IncomeTaxExpense                 int64  `json:"incometaxexpense" bson:"incometaxexpense"`
+	IncomeTaxExpenseNice             string `json:"incometaxexpensenice" bson:"incometaxexpensenice"`
+	MinorityInterest                 int64  `json:"minorityinterest" bson:"minorityinterest"`
+	MinorityInterestNice             string `json:"minorityinterestnice" bson:"minorityinterestnice"`
+	NetIncomeFromContinuingOps       int64  `json:"netincomefromcontinuingops" bson:"netincomefromcontinuingops"`
+	NetIncomeFromContinuingOpsNice   string `json:"netincomefromcontinuingopsnice" bson:"netincomefromcontinuingopsnice"`
+	DiscontinuedOperations           int64  `json:"discontinuedoperations" bson:"discontinuedoperations"`
+	DiscontinuedOperationsNice       string `json:"discontinuedoperationsnice" bson:"discontinuedoperationsnice"`
+	ExtraordinaryItems               int64  `json:"extraordinaryitems" bson:"extraordinaryitems"`
+	ExtraordinaryItemsNice           string `json:"extraordinaryitemsnice" bson:"extraordinaryitemsnice"`
+	EffectOfAccountingCharges        int64  `json:"effectofaccountingcharges" bson:"effectofaccountingcharges"`
+	EffectOfAccountingChargesNice    string `json:"effectofaccountingchargesnice" bson:"effectofaccountingchargesnice"`
+	OtherItems                       int64  `json:"otheritems" bson:"otheritems"`
+	OtherItemsNice                   string `json:"otheritemsnice" bson:"otheritemsnice"`
+	NetIncome                        int64  `json:"netincome" bson:"netincome"`
+	NetIncomeNice                    string `json:"netincomenice" bson:"netincomenice"`
+	NetIncomeCommonShares            int64  `json:"netincomecommonshares" bson:"netincomecommonshares"`
+	NetIncomeCommonSharesNice        string `json:"netincomecommonsharesnice" bson:"netincomecommonsharesnice"`
 }
 type earningsN struct {
-	Date1               string  `bson:"date1"`
-	Date2               string  `bson:"date2"`
-	EarningsAverage     float64 `bson:"earningsaverage"`
-	EarningsAverageNice string  `bson:"earningsaveragenice"`
-	EarningsLow         float64 `bson:"earningslow"`
-	EarningsLowNice     string  `bson:"earningslownice"`
-	EarningsHigh        float64 `bson:"earningshigh"`
-	EarningsHighNice    string  `bson:"earningshighnice"`
-	RevenueAverage      int64   `bson:"revenueaverage"`
-	RevenueAverageNice  string  `bson:"revenueaveragenice"`
-	RevenueLow          int64   `bson:"revenuelow"`
-	RevenueLowNice      string  `bson:"revenuelownice"`
-	RevenueHigh         int64   `bson:"revenuehigh"`
-	RevenueHighNice     string  `bson:"revenuehighnice"`
+	Date1               string  `json:"date1" bson:"date1"`
+	Date2               string  `json:"date2" bson:"date2"`
+	EarningsAverage     float64 `json:"earningsaverage" bson:"earningsaverage"`
+	EarningsAverageNice string  `json:"earningsaveragenice" bson:"earningsaveragenice"`
+	EarningsLow         float64 `json:"earningslow" bson:"earningslow"`
+	EarningsLowNice     string  `json:"earningslownice" bson:"earningslownice"`
+	EarningsHigh        float64 `json:"earningshigh" bson:"earningshigh"`
+	EarningsHighNice    string  `json:"earningshighnice" bson:"earningshighnice"`
+	RevenueAverage      int64   `json:"revenueaverage" bson:"revenueaverage"`
+	RevenueAverageNice  string  `json:"revenueaveragenice" bson:"revenueaveragenice"`
+	RevenueLow          int64   `json:"revenuelow" bson:"revenuelow"`
+	RevenueLowNice      string  `json:"revenuelownice" bson:"revenuelownice"`
+	RevenueHigh         int64   `json:"revenuehigh" bson:"revenuehigh"`
+	RevenueHighNice     string  `json:"revenuehighnice" bson:"revenuehighnice"`
 }
 type balanceH struct {
-	Cash                        int64  `bson:"cash"`
-	CashNice                    string `bson:"cashnice"`
-	ShortTermInvestments        int64  `bson:"shortterminvestments"`
-	ShortTermInvestmentsNice    string `bson:"shortterminvestmentsnice"`
-	NetReceivables              int64  `bson:"netreceivables"`
-	NetReceivablesNice          string `bson:"netreceivablesnice"`
-	Inventory                   int64  `bson:"inventory"`
-	InventoryNice               string `bson:"inventorynice"`
-	OtherCurrentAssets          int64  `bson:"othercurrentassets"`
-	OtherCurrentAssetsNice      string `bson:"othercurrentassetsnice"`
-	TotalCurrentAssets          int64  `bson:"totalcurrentassets"`
-	TotalCurrentAssetsNice      string `bson:"totalcurrentassetsnice"`
-	LongTermInvestments         int64  `bson:"longterminvestments"`
-	LongTermInvestmentsNice     string `bson:"longterminvestmentsnice"`
-	PropertyPlantEquipment      int64  `bson:"propertyplantequipment"`
-	PropertyPlantEquipmentNice  string `bson:"propertyplantequipmentnice"`
-	EndDate                     string `bson:"enddate"`
-	EndDateY                    string `bson:"enddatey"`
-	OtherAssets                 int64  `bson:"otherassets"`
-	OtherAssetsNice             string `bson:"otherassetsnice"`
-	TotalAssets                 int64  `bson:"totalassets"`
-	TotalAssetsNice             string `bson:"totalassetsnice"`
-	AccountsPayable             int64  `bson:"accountspayable"`
-	AccountsPayableNice         string `bson:"accountspayablenice"`
-	ShortLongTermDebt           int64  `bson:"shortlongtermdebt"`
-	ShortLongTermDebtNice       string `bson:"shortlongtermdebtnice"`
-	OtherCurrentLiab            int64  `bson:"othercurrentliab"`
-	OtherCurrentLiabNice        string `bson:"othercurrentliabnice"`
-	LongTermDebt                int64  `bson:"longtermdebt"`
-	LongTermDebtNice            string `bson:"longtermdebtnice"`
-	OtherLiab                   int64  `bson:"otherliab"`
-	OtherLiabNice               string `bson:"otherliabnice"`
-	TotalCurrentLiabilities     int64  `bson:"totalcurrentliabilities"`
-	TotalCurrentLiabilitiesNice string `bson:"totalcurrentliabilitiesnice"`
-	TotalLiab                   int64  `bson:"totalliab"`
-	TotalLiabNice               string `bson:"totalliabnice"`
-	CommonStock                 int64  `bson:"commonstock"`
-	CommonStockNice             string `bson:"commonstocknice"`
-	RetainedEarnings            int64  `bson:"retainedearnings"`
-	RetainedEarningsNice        string `bson:"retainedearningsnice"`
-	TreasuryStock               int64  `bson:"treasurystock"`
-	TreasuryStockNice           string `bson:"treasurystocknice"`
-	OtherStockholderEquity      int64  `bson:"otherstockholderequity"`
-	OtherStockholderEquityNice  string `bson:"otherstockholderequitynice"`
-	TotalStockholderEquity      int64  `bson:"totalstockholderequity"`
-	TotalStockholderEquityNice  string `bson:"totalstockholderequitynice"`
-	NetTangibleAssets           int64  `bson:"nettangibleassets"`
-	NetTangibleAssetsNice       string `bson:"nettangibleassetsnice"`
+	Cash                        int64  `json:"cash" bson:"cash"`
+	CashNice                    string `json:"cashnice" bson:"cashnice"`
+	ShortTermInvestments        int64  `json:"shortterminvestments" bson:"shortterminvestments"`
+	ShortTermInvestmentsNice    string `json:"shortterminvestmentsnice" bson:"shortterminvestmentsnice"`
+	NetReceivables              int64  `json:"netreceivables" bson:"netreceivables"`
+	NetReceivablesNice          string `json:"netreceivablesnice" bson:"netreceivablesnice"`
+	Inventory                   int64  `json:"inventory" bson:"inventory"`
+	InventoryNice               string `json:"inventorynice" bson:"inventorynice"`
+	OtherCurrentAssets          int64  `json:"othercurrentassets" bson:"othercurrentassets"`
+	OtherCurrentAssetsNice      string `json:"othercurrentassetsnice" bson:"othercurrentassetsnice"`
+	TotalCurrentAssets          int64  `json:"totalcurrentassets" bson:"totalcurrentassets"`
+	TotalCurrentAssetsNice      string `json:"totalcurrentassetsnice" bson:"totalcurrentassetsnice"`
+	LongTermInvestments         int64  `json:"longterminvestments" bson:"longterminvestments"`
+	LongTermInvestmentsNice     string `json:"longterminvestmentsnice" bson:"longterminvestmentsnice"`
+	PropertyPlantEquipment      int64  `json:"propertyplantequipment" bson:"propertyplantequipment"`
+	PropertyPlantEquipmentNice  string `json:"propertyplantequipmentnice" bson:"propertyplantequipmentnice"`
+	EndDate                     string `json:"enddate" bson:"enddate"`
+	EndDateY                    string `json:"enddatey" bson:"enddatey"`
+	OtherAssets                 int64  `json:"otherassets" bson:"otherassets"`
+	OtherAssetsNice             string `json:"otherassetsnice" bson:"otherassetsnice"`
+	TotalAssets                 int64  `json:"totalassets" bson:"totalassets"`
+	TotalAssetsNice             string `json:"totalassetsnice" bson:"totalassetsnice"`
+	AccountsPayable             int64  `json:"accountspayable" bson:"accountspayable"`
+	AccountsPayableNice         string `json:"accountspayablenice" bson:"accountspayablenice"`
+	ShortLongTermDebt           int64  `json:"shortlongtermdebt" bson:"shortlongtermdebt"`
+	ShortLongTermDebtNice       string `json:"shortlongtermdebtnice" bson:"shortlongtermdebtnice"`
+	OtherCurrentLiab            int64  `json:"othercurrentliab" bson:"othercurrentliab"`
+	OtherCurrentLiabNice        string `json:"othercurrentliabnice" bson:"othercurrentliabnice"`
+	LongTermDebt                int64  `json:"longtermdebt" bson:"longtermdebt"`
+	LongTermDebtNice            string `json:"longtermdebtnice" bson:"longtermdebtnice"`
+	OtherLiab                   int64  `json:"otherliab" bson:"otherliab"`
+	OtherLiabNice               string `json:"otherliabnice" bson:"otherliabnice"`
+	TotalCurrentLiabilities     int64  `json:"totalcurrentliabilities" bson:"totalcurrentliabilities"`
+	TotalCurrentLiabilitiesNice string `json:"totalcurrentliabilitiesnice" bson:"totalcurrentliabilitiesnice"`
+	TotalLiab                   int64  `json:"totalliab" bson:"totalliab"`
+	TotalLiabNice               string `json:"totalliabnice" bson:"totalliabnice"`
+	CommonStock                 int64  `json:"commonstock" bson:"commonstock"`
+	CommonStockNice             string `json:"commonstocknice" bson:"commonstocknice"`
+	RetainedEarnings            int64  `json:"retainedearnings" bson:"retainedearnings"`
+	RetainedEarningsNice        string `json:"retainedearningsnice" bson:"retainedearningsnice"`
+	TreasuryStock               int64  `json:"treasurystock" bson:"treasurystock"`
+	TreasuryStockNice           string `json:"treasurystocknice" bson:"treasurystocknice"`
+	OtherStockholderEquity      int64  `json:"otherstockholderequity" bson:"otherstockholderequity"`
+	OtherStockholderEquityNice  string `json:"otherstockholderequitynice" bson:"otherstockholderequitynice"`
+	TotalStockholderEquity      int64  `json:"totalstockholderequity" bson:"totalstockholderequity"`
+	TotalStockholderEquityNice  string `json:"totalstockholderequitynice" bson:"totalstockholderequitynice"`
+	NetTangibleAssets           int64  `json:"nettangibleassets" bson:"nettangibleassets"`
+	NetTangibleAssetsNice       string `json:"nettangibleassetsnice" bson:"nettangibleassetsnice"`
 }
 
+// Key is an API key of a provider. A provider can have several keys; see
+// AcquireKey for how Limit, Used and ResetAt are maintained.
 type Key struct {
-	Name string `bson:"name"`
-	Key  string `bson:"key"`
+	ID   primitive.ObjectID `bson:"_id,omitempty"`
+	Name string             `bson:"name"`
+	Key  string             `bson:"key"`
+	// Limit is the number of fetches allowed per window, 0 for no limit.
+	Limit int64 `bson:"limit"`
+	// WindowSeconds is the length of the quota window, one day when 0.
+	WindowSeconds int64     `bson:"windowseconds"`
+	Used          int64     `bson:"used"`
+	ResetAt       time.Time `bson:"resetat"`
+	// Exhausted is set when the provider reported the quota as used up
+	// before Limit was reached. It is cleared when the window resets.
+	Exhausted bool `bson:"exhausted"`
+	// Disabled keys are kept but never handed out by AcquireKey.
+	Disabled bool `bson:"disabled"`
 }
 
 var stocksDataBase = "stocks"
 var stocksColl = "stocks"
 var keyColl = "keys"
 
-func MongoDBConnect(mongoDBServerName, mongoDBServerPort, mongoDBAdminUser, mongoDBAdminUserPassword string) (*mongo.Client, context.Context, context.CancelFunc) {
-	mongoString := "mongodb://" + mongoDBAdminUser + ":" + mongoDBAdminUserPassword + "@" + mongoDBServerName + ":" + mongoDBServerPort
-	ctx, ctxCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoString))
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	return client, ctx, ctxCancel
-}
+// CompetitorsAddress is the host:port of the competitors service.
+var CompetitorsAddress string
 
-func SetCompetitors(ticker, exchange string) {
-	var competitorsServerName = os.Getenv("COMPETITORS_NAME")
-	var competitorsServerPort = os.Getenv("COMPETITORS_PORT")
-
-	var competitorsLink = "http://" + competitorsServerName + ":" + competitorsServerPort + "/competitors?ticker=" + ticker + "&exchange=" + exchange
-	//#nosec G107 -- This is a false positive
-	_, err := http.Get(competitorsLink)
+// PingCompetitors checks that the competitors service accepts connections.
+func PingCompetitors(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", CompetitorsAddress)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	return conn.Close()
 }
 
-func NewStock(cy *yahoodata.YahooData, dbServer, dbPort, dbUser, dbPass string) {
-	client, ctx, ctxCancel := MongoDBConnect(dbServer, dbPort, dbUser, dbPass)
+// CompetitorsClient sends the requests of SetCompetitors.
+var CompetitorsClient = http.DefaultClient
 
-	defer client.Disconnect(ctx)
-	defer ctxCancel()
+// CompetitorsBreaker stops calls to the competitors service after repeated
+// failures. SetCompetitors then returns an error wrapping breaker.ErrOpen.
+var CompetitorsBreaker = breaker.New("competitors", 5, 30*time.Second)
 
-	var stock Stock
-	stock.Name = cy.QuoteSummary.Result[0].Price.ShortName
-	stock.Ticker = cy.QuoteSummary.Result[0].Price.Symbol
-	stock.Exchange = cy.QuoteSummary.Result[0].Price.ExchangeName
-	stock.Beta, _ = strconv.ParseFloat(cy.QuoteSummary.Result[0].DefaultKeyStatistics.Beta.Fmt, 64)
-	stock.Industry = cy.QuoteSummary.Result[0].AssetProfile.Industry
-	stock.Address = cy.QuoteSummary.Result[0].AssetProfile.Address1
-	stock.City = cy.QuoteSummary.Result[0].AssetProfile.City
-	stock.Country = cy.QuoteSummary.Result[0].AssetProfile.Country
-	stock.EmployeeNo = cy.QuoteSummary.Result[0].AssetProfile.FullTimeEmployees
-	stock.Sector = cy.QuoteSummary.Result[0].AssetProfile.Sector
-	stock.RecommTrend = findStockRecomm(cy)
-	insertStockDatabyDate(cy, &stock, "CashFlow")
-	stock.EnterpriseValue = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseValue.Raw
-	stock.EnterpriseValueNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseValue.Fmt
-	stock.ForwardPE = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardPE.Raw
-	stock.ForwardPENice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardPE.Fmt
-	stock.ProfitMargins = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ProfitMargins.Raw
-	stock.ProfitMarginsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ProfitMargins.Fmt
-	stock.FloatShares = cy.QuoteSummary.Result[0].DefaultKeyStatistics.FloatShares.Raw
-	stock.FloatSharesNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.FloatShares.Fmt
-	stock.SharesOutstanding = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesOutstanding.Raw
-	stock.SharesOutstandingNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesOutstanding.Fmt
-	stock.SharesShort = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesShort.Raw
-	stock.SharesShortNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesShort.Fmt
-	stock.HeldPercentInsiders = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInsiders.Raw
-	stock.HeldPercentInsidersNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInsiders.Fmt
-	stock.HeldPercentInstitutions = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInstitutions.Raw
-	stock.HeldPercentInstitutionsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInstitutions.Fmt
-	stock.ShortRatio = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortRatio.Raw
-	stock.ShortRatioNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortRatio.Fmt
-	stock.ShortPercentOfFloat = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortPercentOfFloat.Raw
-	stock.ShortPercentOfFloatNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortPercentOfFloat.Fmt
-	stock.BookValue = cy.QuoteSummary.Result[0].DefaultKeyStatistics.BookValue.Raw
-	stock.BookValueNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.BookValue.Fmt
-	stock.PriceToBook = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PriceToBook.Raw
-	stock.PriceToBookNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PriceToBook.Fmt
-	stock.LastFiscalYearEnd = cy.QuoteSummary.Result[0].DefaultKeyStatistics.LastFiscalYearEnd.Fmt
-	stock.MostRecentQuarter = cy.QuoteSummary.Result[0].DefaultKeyStatistics.MostRecentQuarter.Fmt
-	stock.NetIncomeToCommon = cy.QuoteSummary.Result[0].DefaultKeyStatistics.NetIncomeToCommon.Raw
-	stock.NetIncomeToCommonNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.NetIncomeToCommon.Fmt
-	stock.TrailingEps = cy.QuoteSummary.Result[0].DefaultKeyStatistics.TrailingEps.Raw
-	stock.TrailingEpsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.TrailingEps.Fmt
-	stock.ForwardEps = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardEps.Raw
-	stock.ForwardEpsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardEps.Fmt
-	stock.PegRatio = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PegRatio.Raw
-	stock.PegRatioNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PegRatio.Fmt
-	stock.LastSplitFactor = cy.QuoteSummary.Result[0].DefaultKeyStatistics.LastSplitFactor
-	stock.LastSplitDate = cy.QuoteSummary.Result[0].DefaultKeyStatistics.LastSplitDate.Fmt
-	stock.EnterpriseToRevenue = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToRevenue.Raw
-	stock.EnterpriseToRevenueNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToRevenue.Fmt
-	stock.EnterpriseToEbitda = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToEbitda.Raw
-	stock.EnterpriseToEbitdaNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToEbitda.Fmt
-	stock.WeekChange52 = cy.QuoteSummary.Result[0].DefaultKeyStatistics.WeekChange52.Raw
-	stock.WeekChange52Nice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.WeekChange52.Fmt
-	insertStockDatabyDate(cy, &stock, "Income")
-	stock.Currency = cy.QuoteSummary.Result[0].SummaryDetail.Currency
-	stock.ExDividendDate = cy.QuoteSummary.Result[0].SummaryDetail.ExDividendDate.Fmt
-	stock.DividendRate = cy.QuoteSummary.Result[0].SummaryDetail.DividendRate.Raw
-	stock.DividendRateNice = cy.QuoteSummary.Result[0].SummaryDetail.DividendRate.Fmt
-	stock.DividendYield = cy.QuoteSummary.Result[0].SummaryDetail.DividendYield.Raw
-	stock.DividendYieldNice = cy.QuoteSummary.Result[0].SummaryDetail.DividendYield.Fmt
-	stock.PayoutRatio = cy.QuoteSummary.Result[0].SummaryDetail.PayoutRatio.Raw
-	stock.PayoutRatioNice = cy.QuoteSummary.Result[0].SummaryDetail.PayoutRatio.Fmt
-	stock.TrailingPE = cy.QuoteSummary.Result[0].SummaryDetail.TrailingPE.Raw
-	stock.TrailingPENice = cy.QuoteSummary.Result[0].SummaryDetail.TrailingPE.Fmt
-	stock.MarketCap = cy.QuoteSummary.Result[0].SummaryDetail.MarketCap.Raw
-	stock.MarketCapNice = cy.QuoteSummary.Result[0].SummaryDetail.MarketCap.Fmt
-	if len(cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate) >= 1 {
-		stock.EarningsNext.Date1 = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate[0].Fmt
+// SetCompetitors asks the competitors service to look up the competitors of
+// the stock. The request is cancelled when ctx is done.
+func SetCompetitors(ctx context.Context, ticker, exchange string) error {
+	var competitorsLink = "http://" + CompetitorsAddress + "/competitors?ticker=" + ticker + "&exchange=" + exchange
+	req, err := http.NewRequestWithContext(ctx, "GET", competitorsLink, nil)
+	if err != nil {
+		return err
 	}
-	if len(cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate) >= 2 {
-		stock.EarningsNext.Date2 = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate[1].Fmt
+	if err := CompetitorsBreaker.Allow(); err != nil {
+		return err
+	}
+	resp, err := CompetitorsClient.Do(req)
+	if errors.Is(err, context.Canceled) {
+		CompetitorsBreaker.Ignore()
+		return err
 	}
-	stock.EarningsNext.EarningsAverage = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsAverage.Raw
-	stock.EarningsNext.EarningsAverageNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsAverage.Fmt
-	stock.EarningsNext.EarningsLow = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsLow.Raw
-	stock.EarningsNext.EarningsLowNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsLow.Fmt
-	stock.EarningsNext.EarningsHigh = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsHigh.Raw
-	stock.EarningsNext.EarningsHighNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsHigh.Fmt
-	stock.EarningsNext.RevenueAverage = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueAverage.Raw
-	stock.EarningsNext.RevenueAverageNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueAverage.Fmt
-	stock.EarningsNext.RevenueLow = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueLow.Raw
-	stock.EarningsNext.RevenueLowNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueLow.Fmt
-	stock.EarningsNext.RevenueHigh = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueHigh.Raw
-	stock.EarningsNext.RevenueHighNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueHigh.Fmt
-	insertStockDatabyDate(cy, &stock, "Balance")
-	stock.Growth5y, stock.Growth5yNice = findGrowth(cy)
-	insertStockDatabyDate(cy, &stock, "BalanceQ")
-	insertStockDatabyDate(cy, &stock, "IncomeQ")
-	insertStockDatabyDate(cy, &stock, "CashFlowQ")
-	stock.Price = cy.QuoteSummary.Result[0].FinancialData.CurrentPrice.Raw
-	stock.TargetHighPrice = cy.QuoteSummary.Result[0].FinancialData.TargetHighPrice.Raw
-	stock.TargetLowPrice = cy.QuoteSummary.Result[0].FinancialData.TargetLowPrice.Raw
-	stock.TargetMedianPrice = cy.QuoteSummary.Result[0].FinancialData.TargetMedianPrice.Raw
-	stock.RecommendationKey = cy.QuoteSummary.Result[0].FinancialData.RecommendationKey
-	stock.TotalCash = cy.QuoteSummary.Result[0].FinancialData.TotalCash.Raw
-	stock.TotalCashNice = cy.QuoteSummary.Result[0].FinancialData.TotalCash.Fmt
-	stock.TotalCashPerShare = cy.QuoteSummary.Result[0].FinancialData.TotalCashPerShare.Raw
-	stock.TotalCashPerShareNice = cy.QuoteSummary.Result[0].FinancialData.TotalCashPerShare.Fmt
-	stock.Ebitda = cy.QuoteSummary.Result[0].FinancialData.Ebitda.Raw
-	stock.EbitdaNice = cy.QuoteSummary.Result[0].FinancialData.Ebitda.Fmt
-	stock.TotalDebt = cy.QuoteSummary.Result[0].FinancialData.TotalDebt.Raw
-	stock.TotalDebtNice = cy.QuoteSummary.Result[0].FinancialData.TotalDebt.Fmt
-	stock.QuickRatio = cy.QuoteSummary.Result[0].FinancialData.QuickRatio.Raw
-	stock.QuickRatioNice = cy.QuoteSummary.Result[0].FinancialData.QuickRatio.Fmt
-	stock.CurrentRatio = cy.QuoteSummary.Result[0].FinancialData.CurrentRatio.Raw
-	stock.CurrentRatioNice = cy.QuoteSummary.Result[0].FinancialData.CurrentRatio.Fmt
-	stock.TotalRevenue = cy.QuoteSummary.Result[0].FinancialData.TotalRevenue.Raw
-	stock.TotalRevenueNice = cy.QuoteSummary.Result[0].FinancialData.TotalRevenue.Fmt
-	stock.RevenuePerShare = cy.QuoteSummary.Result[0].FinancialData.RevenuePerShare.Raw
-	stock.RevenuePerShareNice = cy.QuoteSummary.Result[0].FinancialData.RevenuePerShare.Fmt
-	stock.ReturnOnAssets = cy.QuoteSummary.Result[0].FinancialData.ReturnOnAssets.Raw
-	stock.ReturnOnAssetsNice = cy.QuoteSummary.Result[0].FinancialData.ReturnOnAssets.Fmt
-	stock.ReturnOnEquity = cy.QuoteSummary.Result[0].FinancialData.ReturnOnEquity.Raw
-	stock.ReturnOnEquityNice = cy.QuoteSummary.Result[0].FinancialData.ReturnOnEquity.Fmt
-	stock.GrossProfits = cy.QuoteSummary.Result[0].FinancialData.GrossProfits.Raw
-	stock.GrossProfitsNice = cy.QuoteSummary.Result[0].FinancialData.GrossProfits.Fmt
-	stock.FreeCashflow = cy.QuoteSummary.Result[0].FinancialData.FreeCashflow.Raw
-	stock.FreeCashflowNice = cy.QuoteSummary.Result[0].FinancialData.FreeCashflow.Fmt
-	stock.OperatingCashflow = cy.QuoteSummary.Result[0].FinancialData.OperatingCashflow.Raw
-	stock.OperatingCashflowNice = cy.QuoteSummary.Result[0].FinancialData.OperatingCashflow.Fmt
-	stock.GrossMargins = cy.QuoteSummary.Result[0].FinancialData.GrossMargins.Raw
-	stock.GrossMarginsNice = cy.QuoteSummary.Result[0].FinancialData.GrossMargins.Fmt
-	stock.EbitdaMargins = cy.QuoteSummary.Result[0].FinancialData.EbitdaMargins.Raw
-	stock.EbitdaMarginsNice = cy.QuoteSummary.Result[0].FinancialData.EbitdaMargins.Fmt
-	stock.OperatingMargins = cy.QuoteSummary.Result[0].FinancialData.OperatingMargins.Raw
-	stock.OperatingMarginsNice = cy.QuoteSummary.Result[0].FinancialData.OperatingMargins.Fmt
-	stock.DebtToEquity = getDE(cy)
-	stock.ROIC = getROIC(cy)
-	stock.WorkingCapital = getWC(cy)
-	stock.EnterpriseToEbit = getEVToEbit(cy)
-	stock.LastUpdated = time.Now()
-
-	collection := client.Database(stocksDataBase).Collection(stocksColl)
-	_, err := collection.InsertOne(ctx, stock)
 	if err != nil {
-		log.Fatal(err)
+		CompetitorsBreaker.Failure()
+		return err
 	}
-	SetCompetitors(stock.Ticker, stock.Exchange)
-}
-
-func FindStock(ticker, dbServer, dbPort, dbUser, dbPass string) bool {
-
-	var stock Stock
-
-	client, ctx, ctxCancel := MongoDBConnect(dbServer, dbPort, dbUser, dbPass)
-
-	defer client.Disconnect(ctx)
-	defer ctxCancel()
+	resp.Body.Close()
 
-	collection := client.Database(stocksDataBase).Collection(stocksColl)
-
-	if err := collection.FindOne(ctx, bson.M{"ticker": ticker}).Decode(&stock); err != nil {
-		return false
+	if resp.StatusCode >= http.StatusInternalServerError {
+		CompetitorsBreaker.Failure()
 	} else {
-		return true
+		CompetitorsBreaker.Success()
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.New("competitors service returned " + resp.Status)
 	}
+	return nil
 }
 
-func GetStock(ticker, dbServer, dbPort, dbUser, dbPass string) *Stock {
-
-	var stock *Stock
-
-	client, ctx, ctxCancel := MongoDBConnect(dbServer, dbPort, dbUser, dbPass)
-
-	defer client.Disconnect(ctx)
-	defer ctxCancel()
+// NewStock inserts the stock. Competitors are not requested, the caller
+// does that with SetCompetitors once the stock is stored.
+func (s *Store) NewStock(ctx context.Context, f *marketdata.Fundamentals) (*Stock, error) {
+	var stock Stock
+	stock.Ticker = f.Symbol
+	setStockData(f, &stock)
 
-	collection := client.Database(stocksDataBase).Collection(stocksColl)
+	collection := s.db.Collection(stocksColl)
+	res, err := collection.InsertOne(ctx, stock)
+	if err != nil {
+		return nil, err
+	}
+	stock.ID = res.InsertedID.(primitive.ObjectID)
 
-	collection.FindOne(ctx, bson.M{"ticker": ticker}).Decode(&stock)
-	return stock
+	return &stock, nil
 }
 
-func GetKey(name, dbServer, dbPort, dbUser, dbPass string) *Key {
-
-	var key *Key
-
-	client, ctx, ctxCancel := MongoDBConnect(dbServer, dbPort, dbUser, dbPass)
-
-	defer client.Disconnect(ctx)
-	defer ctxCancel()
-
-	collection := client.Database(stocksDataBase).Collection(keyColl)
-
-	collection.FindOne(ctx, bson.M{"name": name}).Decode(&key)
-	return key
+func (s *Store) FindStock(ctx context.Context, ticker string) (bool, error) {
+	stock, err := s.GetStock(ctx, ticker)
+	return stock != nil, err
 }
 
-func UpdateStock(cy *yahoodata.YahooData, ticker, dbServer, dbPort, dbUser, dbPass string) {
-
-	client, ctx, ctxCancel := MongoDBConnect(dbServer, dbPort, dbUser, dbPass)
-
-	defer client.Disconnect(ctx)
-	defer ctxCancel()
+// GetStock returns nil when the ticker is not stored.
+func (s *Store) GetStock(ctx context.Context, ticker string) (*Stock, error) {
+	var stock Stock
+	collection := s.db.Collection(stocksColl)
+	err := collection.FindOne(ctx, bson.M{"ticker": ticker}).Decode(&stock)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
 
-	currentStock := GetStock(ticker, dbServer, dbPort, dbUser, dbPass)
-	if currentStock == nil {
-		log.Fatal("Stock " + ticker + " should exist but was not found")
+// GetKey returns one enabled key of the provider, or nil when there is none.
+func (s *Store) GetKey(ctx context.Context, name string) (*Key, error) {
+	var key Key
+	collection := s.db.Collection(keyColl)
+	err := collection.FindOne(ctx, bson.M{"name": name, "disabled": bson.M{"$ne": true}}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return &key, nil
+}
 
-	currentStock.Name = cy.QuoteSummary.Result[0].Price.ShortName
-	currentStock.Exchange = cy.QuoteSummary.Result[0].Price.ExchangeName
-	currentStock.Beta, _ = strconv.ParseFloat(cy.QuoteSummary.Result[0].DefaultKeyStatistics.Beta.Fmt, 64)
-	currentStock.Industry = cy.QuoteSummary.Result[0].AssetProfile.Industry
-	currentStock.Address = cy.QuoteSummary.Result[0].AssetProfile.Address1
-	currentStock.City = cy.QuoteSummary.Result[0].AssetProfile.City
-	currentStock.Country = cy.QuoteSummary.Result[0].AssetProfile.Country
-	currentStock.EmployeeNo = cy.QuoteSummary.Result[0].AssetProfile.FullTimeEmployees
-	currentStock.Sector = cy.QuoteSummary.Result[0].AssetProfile.Sector
-	currentStock.RecommTrend = findStockRecomm(cy)
-	insertStockDatabyDate(cy, currentStock, "CashFlow")
-	currentStock.EnterpriseValue = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseValue.Raw
-	currentStock.EnterpriseValueNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseValue.Fmt
-	currentStock.ForwardPE = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardPE.Raw
-	currentStock.ForwardPENice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardPE.Fmt
-	currentStock.ProfitMargins = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ProfitMargins.Raw
-	currentStock.ProfitMarginsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ProfitMargins.Fmt
-	currentStock.FloatShares = cy.QuoteSummary.Result[0].DefaultKeyStatistics.FloatShares.Raw
-	currentStock.FloatSharesNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.FloatShares.Fmt
-	currentStock.SharesOutstanding = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesOutstanding.Raw
-	currentStock.SharesOutstandingNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesOutstanding.Fmt
-	currentStock.SharesShort = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesShort.Raw
-	currentStock.SharesShortNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.SharesShort.Fmt
-	currentStock.HeldPercentInsiders = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInsiders.Raw
-	currentStock.HeldPercentInsidersNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInsiders.Fmt
-	currentStock.HeldPercentInstitutions = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInstitutions.Raw
-	currentStock.HeldPercentInstitutionsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.HeldPercentInstitutions.Fmt
-	currentStock.ShortRatio = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortRatio.Raw
-	currentStock.ShortRatioNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortRatio.Fmt
-	currentStock.ShortPercentOfFloat = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortPercentOfFloat.Raw
-	currentStock.ShortPercentOfFloatNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ShortPercentOfFloat.Fmt
-	currentStock.BookValue = cy.QuoteSummary.Result[0].DefaultKeyStatistics.BookValue.Raw
-	currentStock.BookValueNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.BookValue.Fmt
-	currentStock.PriceToBook = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PriceToBook.Raw
-	currentStock.PriceToBookNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PriceToBook.Fmt
-	currentStock.LastFiscalYearEnd = cy.QuoteSummary.Result[0].DefaultKeyStatistics.LastFiscalYearEnd.Fmt
-	currentStock.MostRecentQuarter = cy.QuoteSummary.Result[0].DefaultKeyStatistics.MostRecentQuarter.Fmt
-	currentStock.NetIncomeToCommon = cy.QuoteSummary.Result[0].DefaultKeyStatistics.NetIncomeToCommon.Raw
-	currentStock.NetIncomeToCommonNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.NetIncomeToCommon.Fmt
-	currentStock.TrailingEps = cy.QuoteSummary.Result[0].DefaultKeyStatistics.TrailingEps.Raw
-	currentStock.TrailingEpsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.TrailingEps.Fmt
-	currentStock.ForwardEps = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardEps.Raw
-	currentStock.ForwardEpsNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.ForwardEps.Fmt
-	currentStock.PegRatio = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PegRatio.Raw
-	currentStock.PegRatioNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.PegRatio.Fmt
-	currentStock.LastSplitFactor = cy.QuoteSummary.Result[0].DefaultKeyStatistics.LastSplitFactor
-	currentStock.LastSplitDate = cy.QuoteSummary.Result[0].DefaultKeyStatistics.LastSplitDate.Fmt
-	currentStock.EnterpriseToRevenue = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToRevenue.Raw
-	currentStock.EnterpriseToRevenueNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToRevenue.Fmt
-	currentStock.EnterpriseToEbitda = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToEbitda.Raw
-	currentStock.EnterpriseToEbitdaNice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseToEbitda.Fmt
-	currentStock.WeekChange52 = cy.QuoteSummary.Result[0].DefaultKeyStatistics.WeekChange52.Raw
-	currentStock.WeekChange52Nice = cy.QuoteSummary.Result[0].DefaultKeyStatistics.WeekChange52.Fmt
-	insertStockDatabyDate(cy, currentStock, "Income")
-	currentStock.Currency = cy.QuoteSummary.Result[0].SummaryDetail.Currency
-	currentStock.ExDividendDate = cy.QuoteSummary.Result[0].SummaryDetail.ExDividendDate.Fmt
-	currentStock.DividendRate = cy.QuoteSummary.Result[0].SummaryDetail.DividendRate.Raw
-	currentStock.DividendRateNice = cy.QuoteSummary.Result[0].SummaryDetail.DividendRate.Fmt
-	currentStock.DividendYield = cy.QuoteSummary.Result[0].SummaryDetail.DividendYield.Raw
-	currentStock.DividendYieldNice = cy.QuoteSummary.Result[0].SummaryDetail.DividendYield.Fmt
-	currentStock.PayoutRatio = cy.QuoteSummary.Result[0].SummaryDetail.PayoutRatio.Raw
-	currentStock.PayoutRatioNice = cy.QuoteSummary.Result[0].SummaryDetail.PayoutRatio.Fmt
-	currentStock.TrailingPE = cy.QuoteSummary.Result[0].SummaryDetail.TrailingPE.Raw
-	currentStock.TrailingPENice = cy.QuoteSummary.Result[0].SummaryDetail.TrailingPE.Fmt
-	currentStock.MarketCap = cy.QuoteSummary.Result[0].SummaryDetail.MarketCap.Raw
-	currentStock.MarketCapNice = cy.QuoteSummary.Result[0].SummaryDetail.MarketCap.Fmt
-	if len(cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate) >= 1 {
-		currentStock.EarningsNext.Date1 = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate[0].Fmt
+func (s *Store) UpdateStock(ctx context.Context, f *marketdata.Fundamentals, ticker string) (*Stock, error) {
+	currentStock, err := s.GetStock(ctx, ticker)
+	if err != nil {
+		return nil, err
 	}
-	if len(cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate) >= 2 {
-		currentStock.EarningsNext.Date2 = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate[1].Fmt
+	if currentStock == nil {
+		return nil, errors.New("stock " + ticker + " should exist but was not found")
 	}
-	currentStock.EarningsNext.EarningsAverage = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsAverage.Raw
-	currentStock.EarningsNext.EarningsAverageNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsAverage.Fmt
-	currentStock.EarningsNext.EarningsLow = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsLow.Raw
-	currentStock.EarningsNext.EarningsLowNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsLow.Fmt
-	currentStock.EarningsNext.EarningsHigh = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsHigh.Raw
-	currentStock.EarningsNext.EarningsHighNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsHigh.Fmt
-	currentStock.EarningsNext.RevenueAverage = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueAverage.Raw
-	currentStock.EarningsNext.RevenueAverageNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueAverage.Fmt
-	currentStock.EarningsNext.RevenueLow = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueLow.Raw
-	currentStock.EarningsNext.RevenueLowNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueLow.Fmt
-	currentStock.EarningsNext.RevenueHigh = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueHigh.Raw
-	currentStock.EarningsNext.RevenueHighNice = cy.QuoteSummary.Result[0].CalendarEvents.Earnings.RevenueHigh.Fmt
-	insertStockDatabyDate(cy, currentStock, "Balance")
-	currentStock.Growth5y, currentStock.Growth5yNice = findGrowth(cy)
-	insertStockDatabyDate(cy, currentStock, "BalanceQ")
-	insertStockDatabyDate(cy, currentStock, "IncomeQ")
-	insertStockDatabyDate(cy, currentStock, "CashFlowQ")
-	currentStock.Price = cy.QuoteSummary.Result[0].FinancialData.CurrentPrice.Raw
-	currentStock.TargetHighPrice = cy.QuoteSummary.Result[0].FinancialData.TargetHighPrice.Raw
-	currentStock.TargetLowPrice = cy.QuoteSummary.Result[0].FinancialData.TargetLowPrice.Raw
-	currentStock.TargetMedianPrice = cy.QuoteSummary.Result[0].FinancialData.TargetMedianPrice.Raw
-	currentStock.RecommendationKey = cy.QuoteSummary.Result[0].FinancialData.RecommendationKey
-	currentStock.TotalCash = cy.QuoteSummary.Result[0].FinancialData.TotalCash.Raw
-	currentStock.TotalCashNice = cy.QuoteSummary.Result[0].FinancialData.TotalCash.Fmt
-	currentStock.TotalCashPerShare = cy.QuoteSummary.Result[0].FinancialData.TotalCashPerShare.Raw
-	currentStock.TotalCashPerShareNice = cy.QuoteSummary.Result[0].FinancialData.TotalCashPerShare.Fmt
-	currentStock.Ebitda = cy.QuoteSummary.Result[0].FinancialData.Ebitda.Raw
-	currentStock.EbitdaNice = cy.QuoteSummary.Result[0].FinancialData.Ebitda.Fmt
-	currentStock.TotalDebt = cy.QuoteSummary.Result[0].FinancialData.TotalDebt.Raw
-	currentStock.TotalDebtNice = cy.QuoteSummary.Result[0].FinancialData.TotalDebt.Fmt
-	currentStock.QuickRatio = cy.QuoteSummary.Result[0].FinancialData.QuickRatio.Raw
-	currentStock.QuickRatioNice = cy.QuoteSummary.Result[0].FinancialData.QuickRatio.Fmt
-	currentStock.CurrentRatio = cy.QuoteSummary.Result[0].FinancialData.CurrentRatio.Raw
-	currentStock.CurrentRatioNice = cy.QuoteSummary.Result[0].FinancialData.CurrentRatio.Fmt
-	currentStock.TotalRevenue = cy.QuoteSummary.Result[0].FinancialData.TotalRevenue.Raw
-	currentStock.TotalRevenueNice = cy.QuoteSummary.Result[0].FinancialData.TotalRevenue.Fmt
-	currentStock.RevenuePerShare = cy.QuoteSummary.Result[0].FinancialData.RevenuePerShare.Raw
-	currentStock.RevenuePerShareNice = cy.QuoteSummary.Result[0].FinancialData.RevenuePerShare.Fmt
-	currentStock.ReturnOnAssets = cy.QuoteSummary.Result[0].FinancialData.ReturnOnAssets.Raw
-	currentStock.ReturnOnAssetsNice = cy.QuoteSummary.Result[0].FinancialData.ReturnOnAssets.Fmt
-	currentStock.ReturnOnEquity = cy.QuoteSummary.Result[0].FinancialData.ReturnOnEquity.Raw
-	currentStock.ReturnOnEquityNice = cy.QuoteSummary.Result[0].FinancialData.ReturnOnEquity.Fmt
-	currentStock.GrossProfits = cy.QuoteSummary.Result[0].FinancialData.GrossProfits.Raw
-	currentStock.GrossProfitsNice = cy.QuoteSummary.Result[0].FinancialData.GrossProfits.Fmt
-	currentStock.FreeCashflow = cy.QuoteSummary.Result[0].FinancialData.FreeCashflow.Raw
-	currentStock.FreeCashflowNice = cy.QuoteSummary.Result[0].FinancialData.FreeCashflow.Fmt
-	currentStock.OperatingCashflow = cy.QuoteSummary.Result[0].FinancialData.OperatingCashflow.Raw
-	currentStock.OperatingCashflowNice = cy.QuoteSummary.Result[0].FinancialData.OperatingCashflow.Fmt
-	currentStock.GrossMargins = cy.QuoteSummary.Result[0].FinancialData.GrossMargins.Raw
-	currentStock.GrossMarginsNice = cy.QuoteSummary.Result[0].FinancialData.GrossMargins.Fmt
-	currentStock.EbitdaMargins = cy.QuoteSummary.Result[0].FinancialData.EbitdaMargins.Raw
-	currentStock.EbitdaMarginsNice = cy.QuoteSummary.Result[0].FinancialData.EbitdaMargins.Fmt
-	currentStock.OperatingMargins = cy.QuoteSummary.Result[0].FinancialData.OperatingMargins.Raw
-	currentStock.OperatingMarginsNice = cy.QuoteSummary.Result[0].FinancialData.OperatingMargins.Fmt
-	currentStock.DebtToEquity = getDE(cy)
-	currentStock.ROIC = getROIC(cy)
-	currentStock.WorkingCapital = getWC(cy)
-	currentStock.EnterpriseToEbit = getEVToEbit(cy)
-	currentStock.LastUpdated = time.Now()
+
+	setStockData(f, currentStock)
 
 	pByte, err := bson.Marshal(currentStock)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	collection := client.Database(stocksDataBase).Collection(stocksColl)
+	collection := s.db.Collection(stocksColl)
 	filter := bson.M{"ticker": bson.M{"$eq": ticker}}
 	var update bson.M
 	err = bson.Unmarshal(pByte, &update)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	_, err = collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: update}})
-
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	SetCompetitors(currentStock.Ticker, currentStock.Exchange)
+	return currentStock, nil
 }
 
-func findStockRecomm(cy *yahoodata.YahooData) recommTrend {
-	var ret recommTrend
+func setStockData(f *marketdata.Fundamentals, s *Stock) {
+	s.Name = f.Name
+	s.Exchange = f.Exchange
+	s.Beta, _ = strconv.ParseFloat(f.KeyStatistics.Beta.Display, 64)
+	s.Industry = f.Profile.Industry
+	s.Address = f.Profile.Address
+	s.City = f.Profile.City
+	s.Country = f.Profile.Country
+	s.EmployeeNo = f.Profile.Employees
+	s.Sector = f.Profile.Sector
+	s.RecommTrend = findStockRecomm(f)
+	insertStockDatabyDate(f, s, "CashFlow")
+	s.EnterpriseValue = f.KeyStatistics.EnterpriseValue.Value
+	s.EnterpriseValueNice = f.KeyStatistics.EnterpriseValue.Display
+	s.ForwardPE = f.KeyStatistics.ForwardPE.Value
+	s.ForwardPENice = f.KeyStatistics.ForwardPE.Display
+	s.ProfitMargins = f.KeyStatistics.ProfitMargins.Value
+	s.ProfitMarginsNice = f.KeyStatistics.ProfitMargins.Display
+	s.FloatShares = f.KeyStatistics.FloatShares.Value
+	s.FloatSharesNice = f.KeyStatistics.FloatShares.Display
+	s.SharesOutstanding = f.KeyStatistics.SharesOutstanding.Value
+	s.SharesOutstandingNice = f.KeyStatistics.SharesOutstanding.Display
+	s.SharesShort = f.KeyStatistics.SharesShort.Value
+	s.SharesShortNice = f.KeyStatistics.SharesShort.Display
+	s.HeldPercentInsiders = f.KeyStatistics.HeldPercentInsiders.Value
+	s.HeldPercentInsidersNice = f.KeyStatistics.HeldPercentInsiders.Display
+	s.HeldPercentInstitutions = f.KeyStatistics.HeldPercentInstitutions.Value
+	s.HeldPercentInstitutionsNice = f.KeyStatistics.HeldPercentInstitutions.Display
+	s.ShortRatio = f.KeyStatistics.ShortRatio.Value
+	s.ShortRatioNice = f.KeyStatistics.ShortRatio.Display
+	s.ShortPercentOfFloat = f.KeyStatistics.ShortPercentOfFloat.Value
+	s.ShortPercentOfFloatNice = f.KeyStatistics.ShortPercentOfFloat.Display
+	s.BookValue = f.KeyStatistics.BookValue.Value
+	s.BookValueNice = f.KeyStatistics.BookValue.Display
+	s.PriceToBook = f.KeyStatistics.PriceToBook.Value
+	s.PriceToBookNice = f.KeyStatistics.PriceToBook.Display
+	s.LastFiscalYearEnd = f.KeyStatistics.LastFiscalYearEnd
+	s.MostRecentQuarter = f.KeyStatistics.MostRecentQuarter
+	s.NetIncomeToCommon = f.KeyStatistics.NetIncomeToCommon.Value
+	s.NetIncomeToCommonNice = f.KeyStatistics.NetIncomeToCommon.Display
+	s.TrailingEps = f.KeyStatistics.TrailingEps.Value
+	s.TrailingEpsNice = f.KeyStatistics.TrailingEps.Display
+	s.ForwardEps = f.KeyStatistics.ForwardEps.Value
+	s.ForwardEpsNice = f.KeyStatistics.ForwardEps.Display
+	s.PegRatio = f.KeyStatistics.PegRatio.Value
+	s.PegRatioNice = f.KeyStatistics.PegRatio.Display
+	s.LastSplitFactor = f.KeyStatistics.LastSplitFactor
+	s.LastSplitDate = f.KeyStatistics.LastSplitDate
+	s.EnterpriseToRevenue = f.KeyStatistics.EnterpriseToRevenue.Value
+	s.EnterpriseToRevenueNice = f.KeyStatistics.EnterpriseToRevenue.Display
+	s.EnterpriseToEbitda = f.KeyStatistics.EnterpriseToEbitda.Value
+	s.EnterpriseToEbitdaNice = f.KeyStatistics.EnterpriseToEbitda.Display
+	s.WeekChange52 = f.KeyStatistics.WeekChange52.Value
+	s.WeekChange52Nice = f.KeyStatistics.WeekChange52.Display
+	insertStockDatabyDate(f, s, "Income")
+	s.Currency = f.Currency
+	s.ExDividendDate = f.Summary.ExDividendDate
+	s.DividendRate = f.Summary.DividendRate.Value
+	s.DividendRateNice = f.Summary.DividendRate.Display
+	s.DividendYield = f.Summary.DividendYield.Value
+	s.DividendYieldNice = f.Summary.DividendYield.Display
+	s.PayoutRatio = f.Summary.PayoutRatio.Value
+	s.PayoutRatioNice = f.Summary.PayoutRatio.Display
+	s.TrailingPE = f.Summary.TrailingPE.Value
+	s.TrailingPENice = f.Summary.TrailingPE.Display
+	s.MarketCap = f.Summary.MarketCap.Value
+	s.MarketCapNice = f.Summary.MarketCap.Display
+	if len(f.NextEarnings.Dates) >= 1 {
+		s.EarningsNext.Date1 = f.NextEarnings.Dates[0]
+	}
+	if len(f.NextEarnings.Dates) >= 2 {
+		s.EarningsNext.Date2 = f.NextEarnings.Dates[1]
+	}
+	s.EarningsNext.EarningsAverage = f.NextEarnings.EarningsAverage.Value
+	s.EarningsNext.EarningsAverageNice = f.NextEarnings.EarningsAverage.Display
+	s.EarningsNext.EarningsLow = f.NextEarnings.EarningsLow.Value
+	s.EarningsNext.EarningsLowNice = f.NextEarnings.EarningsLow.Display
+	s.EarningsNext.EarningsHigh = f.NextEarnings.EarningsHigh.Value
+	s.EarningsNext.EarningsHighNice = f.NextEarnings.EarningsHigh.Display
+	s.EarningsNext.RevenueAverage = f.NextEarnings.RevenueAverage.Value
+	s.EarningsNext.RevenueAverageNice = f.NextEarnings.RevenueAverage.Display
+	s.EarningsNext.RevenueLow = f.NextEarnings.RevenueLow.Value
+	s.EarningsNext.RevenueLowNice = f.NextEarnings.RevenueLow.Display
+	s.EarningsNext.RevenueHigh = f.NextEarnings.RevenueHigh.Value
+	s.EarningsNext.RevenueHighNice = f.NextEarnings.RevenueHigh.Display
+	insertStockDatabyDate(f, s, "Balance")
+	s.Growth5y, s.Growth5yNice = findGrowth(f)
+	insertStockDatabyDate(f, s, "BalanceQ")
+	insertStockDatabyDate(f, s, "IncomeQ")
+	insertStockDatabyDate(f, s, "CashFlowQ")
+	s.Price = f.Financial.CurrentPrice.Value
+	s.TargetHighPrice = f.Financial.TargetHighPrice.Value
+	s.TargetLowPrice = f.Financial.TargetLowPrice.Value
+	s.TargetMedianPrice = f.Financial.TargetMedianPrice.Value
+	s.RecommendationKey = f.Financial.RecommendationKey
+	s.TotalCash = f.Financial.TotalCash.Value
+	s.TotalCashNice = f.Financial.TotalCash.Display
+	s.TotalCashPerShare = f.Financial.TotalCashPerShare.Value
+	s.TotalCashPerShareNice = f.Financial.TotalCashPerShare.Display
+	s.Ebitda = f.Financial.Ebitda.Value
+	s.EbitdaNice = f.Financial.Ebitda.Display
+	s.TotalDebt = f.Financial.TotalDebt.Value
+	s.TotalDebtNice = f.Financial.TotalDebt.Display
+	s.QuickRatio = f.Financial.QuickRatio.Value
+	s.QuickRatioNice = f.Financial.QuickRatio.Display
+	s.CurrentRatio = f.Financial.CurrentRatio.Value
+	s.CurrentRatioNice = f.Financial.CurrentRatio.Display
+	s.TotalRevenue = f.Financial.TotalRevenue.Value
+	s.TotalRevenueNice = f.Financial.TotalRevenue.Display
+	s.RevenuePerShare = f.Financial.RevenuePerShare.Value
+	s.RevenuePerShareNice = f.Financial.RevenuePerShare.Display
+	s.ReturnOnAssets = f.Financial.ReturnOnAssets.Value
+	s.ReturnOnAssetsNice = f.Financial.ReturnOnAssets.Display
+	s.ReturnOnEquity = f.Financial.ReturnOnEquity.Value
+	s.ReturnOnEquityNice = f.Financial.ReturnOnEquity.Display
+	s.GrossProfits = f.Financial.GrossProfits.Value
+	s.GrossProfitsNice = f.Financial.GrossProfits.Display
+	s.FreeCashflow = f.Financial.FreeCashflow.Value
+	s.FreeCashflowNice = f.Financial.FreeCashflow.Display
+	s.OperatingCashflow = f.Financial.OperatingCashflow.Value
+	s.OperatingCashflowNice = f.Financial.OperatingCashflow.Display
+	s.GrossMargins = f.Financial.GrossMargins.Value
+	s.GrossMarginsNice = f.Financial.GrossMargins.Display
+	s.EbitdaMargins = f.Financial.EbitdaMargins.Value
+	s.EbitdaMarginsNice = f.Financial.EbitdaMargins.Display
+	s.OperatingMargins = f.Financial.OperatingMargins.Value
+	s.OperatingMarginsNice = f.Financial.OperatingMargins.Display
+	s.DebtToEquity = getDE(f)
+	s.ROIC = getROIC(f)
+	s.WorkingCapital = getWC(f)
+	s.EnterpriseToEbit = getEVToEbit(f)
+	s.LastUpdated = time.Now()
+}
 
-	for _, elem := range cy.QuoteSummary.Result[0].RecommendationTrend.Trend {
-		if elem.Period == "0m" {
-			ret.StrongBuy = elem.StrongBuy
-			ret.Buy = elem.Buy
-			ret.Hold = elem.Hold
-			ret.StrongSell = elem.StrongSell
-			ret.Sell = elem.Sell
+func findStockRecomm(f *marketdata.Fundamentals) recommTrend {
+	var ret recommTrend
 
-			break
-		}
-	}
+	ret.StrongBuy = f.Recommendation.StrongBuy
+	ret.Buy = f.Recommendation.Buy
+	ret.Hold = f.Recommendation.Hold
+	ret.StrongSell = f.Recommendation.StrongSell
+	ret.Sell = f.Recommendation.Sell
 
 	return ret
 }
 
-func findGrowth(cy *yahoodata.YahooData) (float64, string) {
-	var r1 float64
-	var r2 string
-	for _, elem := range cy.QuoteSummary.Result[0].EarningsTrend.Trend {
-		if elem.Period == "+5y" {
-			r1 = elem.Growth.Raw
-			r2 = elem.Growth.Fmt
-		}
-	}
-	return r1, r2
+func findGrowth(f *marketdata.Fundamentals) (float64, string) {
+	return f.Growth5y.Value, f.Growth5y.Display
 }
 
-func getDE(cy *yahoodata.YahooData) float64 {
+func getDE(f *marketdata.Fundamentals) Ratio {
 
-	n1 := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[0].TotalLiab.Raw
-	n2 := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[0].TotalStockholderEquity.Raw
+	if len(f.BalanceQuarterly) == 0 {
+		return Ratio(math.NaN())
+	}
 
-	return math.Round((float64(n1)/float64(n2))*100) / 100
+	n1 := f.BalanceQuarterly[0].TotalLiab.Value
+	n2 := f.BalanceQuarterly[0].TotalStockholderEquity.Value
+
+	return Ratio(math.Round((float64(n1)/float64(n2))*100) / 100)
 }
 
-func getROIC(cy *yahoodata.YahooData) float64 {
+func getROIC(f *marketdata.Fundamentals) Ratio {
+
+	if len(f.Income) == 0 || len(f.BalanceQuarterly) == 0 {
+		return Ratio(math.NaN())
+	}
 
-	ebit := cy.QuoteSummary.Result[0].IncomeStatementHistory.IncomeStatementHistory[0].Ebit.Raw
-	taxexpense := cy.QuoteSummary.Result[0].IncomeStatementHistory.IncomeStatementHistory[0].IncomeTaxExpense.Raw
-	longdebt := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[0].LongTermDebt.Raw
-	stockequity := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[0].TotalStockholderEquity.Raw
-	cash := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[0].Cash.Raw
+	ebit := f.Income[0].Ebit.Value
+	taxexpense := f.Income[0].IncomeTaxExpense.Value
+	longdebt := f.BalanceQuarterly[0].LongTermDebt.Value
+	stockequity := f.BalanceQuarterly[0].TotalStockholderEquity.Value
+	cash := f.BalanceQuarterly[0].Cash.Value
 
-	return (math.Round(((float64(ebit)-float64(taxexpense))/(float64(longdebt)+float64(stockequity)-float64(cash)))*100) / 100) * 100
+	return Ratio((math.Round(((float64(ebit)-float64(taxexpense))/(float64(longdebt)+float64(stockequity)-float64(cash)))*100) / 100) * 100)
 }
 
-func getWC(cy *yahoodata.YahooData) int64 {
+func getWC(f *marketdata.Fundamentals) int64 {
 
-	cl := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[0].TotalCurrentLiabilities.Raw
-	ca := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[0].TotalCurrentAssets.Raw
+	if len(f.BalanceQuarterly) == 0 {
+		return 0
+	}
+
+	cl := f.BalanceQuarterly[0].TotalCurrentLiabilities.Value
+	ca := f.BalanceQuarterly[0].TotalCurrentAssets.Value
 
 	return ca - cl
 }
 
-func getEVToEbit(cy *yahoodata.YahooData) float64 {
-	ev := cy.QuoteSummary.Result[0].DefaultKeyStatistics.EnterpriseValue.Raw
-	ebit := cy.QuoteSummary.Result[0].IncomeStatementHistory.IncomeStatementHistory[0].Ebit.Raw
+func getEVToEbit(f *marketdata.Fundamentals) Ratio {
+	if len(f.Income) == 0 {
+		return Ratio(math.NaN())
+	}
+
+	ev := f.KeyStatistics.EnterpriseValue.Value
+	ebit := f.Income[0].Ebit.Value
 
-	return math.Round(float64(ev)/float64(ebit)*100) / 100
+	return Ratio(math.Round(float64(ev)/float64(ebit)*100) / 100)
 }
 
 func getYear(endDate string) string {
@@ -776,58 +676,58 @@ func getQuarter(endDate string) string {
 	return year + "-" + quarter
 }
 
-func insertStockDatabyDate(cy *yahoodata.YahooData, s *Stock, t string) {
+func insertStockDatabyDate(f *marketdata.Fundamentals, s *Stock, t string) {
 
 	switch t {
 	case "CashFlow":
-		for i := len(cy.QuoteSummary.Result[0].CashflowStatementHistory.CashflowStatements) - 1; i >= 0; i-- {
-			elem := cy.QuoteSummary.Result[0].CashflowStatementHistory.CashflowStatements[i]
+		for i := len(f.CashFlow) - 1; i >= 0; i-- {
+			elem := f.CashFlow[i]
 			found := false
 			for i, elemdb := range s.CashFlowH {
 				s.CashFlowH[i].EndDateY = getYear(elemdb.EndDate)
-				if elem.EndDate.Fmt == elemdb.EndDate {
+				if elem.EndDate == elemdb.EndDate {
 					found = true
 					break
 				}
 			}
 			if !found {
 				var c cashFlowH
-				c.CapEx = elem.CapitalExpenditures.Raw
-				c.CapExNice = elem.CapitalExpenditures.Fmt
-				c.ChangeCash = elem.ChangeInCash.Raw
-				c.ChangeCashNice = elem.ChangeInCash.Fmt
-				c.ChangeAccountReceivables = elem.ChangeToAccountReceivables.Raw
-				c.ChangeAccountReceivablesNice = elem.ChangeToAccountReceivables.Fmt
-				c.ChangeInventory = elem.ChangeToInventory.Raw
-				c.ChangeInventoryNice = elem.ChangeToInventory.Fmt
-				c.ChangeLiabilities = elem.ChangeToLiabilities.Raw
-				c.ChangeLiabilitiesNice = elem.ChangeToLiabilities.Fmt
-				c.ChangeNetIncome = elem.ChangeToNetincome.Raw
-				c.ChangeNetIncomeNice = elem.ChangeToNetincome.Fmt
-				c.Depreciation = elem.Depreciation.Raw
-				c.DepreciationNice = elem.Depreciation.Fmt
-				c.EffectExchangeRate = elem.EffectOfExchangeRate.Raw
-				c.EffectExchangeRateNice = elem.EffectOfExchangeRate.Fmt
-				c.EndDate = elem.EndDate.Fmt
-				c.Investments = elem.Investments.Raw
-				c.InvestmentsNice = elem.Investments.Fmt
-				c.NetBorrowings = elem.NetBorrowings.Raw
-				c.NetBorrowingsNice = elem.NetBorrowings.Fmt
-				c.NetIncome = elem.NetIncome.Raw
-				c.NetIncomeNice = elem.NetIncome.Fmt
-				c.OtherCashFinancing = elem.OtherCashflowsFromFinancingActivities.Raw
-				c.OtherCashFinancingNice = elem.OtherCashflowsFromFinancingActivities.Fmt
-				c.OtherCashInvesting = elem.OtherCashflowsFromInvestingActivities.Raw
-				c.OtherCashInvestingNice = elem.OtherCashflowsFromInvestingActivities.Fmt
-				c.RepurchaseStock = elem.RepurchaseOfStock.Raw
-				c.RepurchaseStockNice = elem.RepurchaseOfStock.Fmt
-				c.TotalCashInvesting = elem.TotalCashflowsFromInvestingActivities.Raw
-				c.TotalCashInvestingNice = elem.TotalCashflowsFromInvestingActivities.Fmt
-				c.TotalCashFinancing = elem.TotalCashFromFinancingActivities.Raw
-				c.TotalCashFinancingNice = elem.TotalCashFromFinancingActivities.Fmt
-				c.TotalCashOperating = elem.TotalCashFromOperatingActivities.Raw
-				c.TotalCashOperatingNice = elem.TotalCashFromOperatingActivities.Fmt
-				c.EndDateY = getYear(elem.EndDate.Fmt)
+				c.CapEx = elem.CapEx.Value
+				c.CapExNice = elem.CapEx.Display
+				c.ChangeCash = elem.ChangeCash.Value
+				c.ChangeCashNice = elem.ChangeCash.Display
+				c.ChangeAccountReceivables = elem.ChangeAccountReceivables.Value
+				c.ChangeAccountReceivablesNice = elem.ChangeAccountReceivables.Display
+				c.ChangeInventory = elem.ChangeInventory.Value
+				c.ChangeInventoryNice = elem.ChangeInventory.Display
+				c.ChangeLiabilities = elem.ChangeLiabilities.Value
+				c.ChangeLiabilitiesNice = elem.ChangeLiabilities.Display
+				c.ChangeNetIncome = elem.ChangeNetIncome.Value
+				c.ChangeNetIncomeNice = elem.ChangeNetIncome.Display
+				c.Depreciation = elem.Depreciation.Value
+				c.DepreciationNice = elem.Depreciation.Display
+				c.EffectExchangeRate = elem.EffectExchangeRate.Value
+				c.EffectExchangeRateNice = elem.EffectExchangeRate.Display
+				c.EndDate = elem.EndDate
+				c.Investments = elem.Investments.Value
+				c.InvestmentsNice = elem.Investments.Display
+				c.NetBorrowings = elem.NetBorrowings.Value
+				c.NetBorrowingsNice = elem.NetBorrowings.Display
+				c.NetIncome = elem.NetIncome.Value
+				c.NetIncomeNice = elem.NetIncome.Display
+				c.OtherCashFinancing = elem.OtherCashFinancing.Value
+				c.OtherCashFinancingNice = elem.OtherCashFinancing.Display
+				c.OtherCashInvesting = elem.OtherCashInvesting.Value
+				c.OtherCashInvestingNice = elem.OtherCashInvesting.Display
+				c.RepurchaseStock = elem.RepurchaseStock.Value
+				c.RepurchaseStockNice = elem.RepurchaseStock.Display
+				c.TotalCashInvesting = elem.TotalCashInvesting.Value
+				c.TotalCashInvestingNice = elem.TotalCashInvesting.Display
+				c.TotalCashFinancing = elem.TotalCashFinancing.Value
+				c.TotalCashFinancingNice = elem.TotalCashFinancing.Display
+				c.TotalCashOperating = elem.TotalCashOperating.Value
+				c.TotalCashOperatingNice = elem.TotalCashOperating.Display
+				c.EndDateY = getYear(elem.EndDate)
 
 				if c.TotalCashOperating != 0 {
 					s.CashFlowH = append(s.CashFlowH, c)
@@ -835,54 +735,54 @@ func insertStockDatabyDate(cy *yahoodata.YahooData, s *Stock, t string) {
 			}
 		}
 	case "CashFlowQ":
-		for i := len(cy.QuoteSummary.Result[0].CashflowStatementHistoryQuarterly.CashflowStatements) - 1; i >= 0; i-- {
-			elem := cy.QuoteSummary.Result[0].CashflowStatementHistoryQuarterly.CashflowStatements[i]
+		for i := len(f.CashFlowQuarterly) - 1; i >= 0; i-- {
+			elem := f.CashFlowQuarterly[i]
 			found := false
 			for i, elemdb := range s.CashFlowHQ {
 				s.CashFlowHQ[i].EndDateY = getQuarter(elemdb.EndDate)
-				if elem.EndDate.Fmt == elemdb.EndDate {
+				if elem.EndDate == elemdb.EndDate {
 					found = true
 					break
 				}
 			}
 			if !found {
 				var c cashFlowH
-				c.CapEx = elem.CapitalExpenditures.Raw
-				c.CapExNice = elem.CapitalExpenditures.Fmt
-				c.ChangeCash = elem.ChangeInCash.Raw
-				c.ChangeCashNice = elem.ChangeInCash.Fmt
-				c.ChangeAccountReceivables = elem.ChangeToAccountReceivables.Raw
-				c.ChangeAccountReceivablesNice = elem.ChangeToAccountReceivables.Fmt
-				c.ChangeInventory = elem.ChangeToInventory.Raw
-				c.ChangeInventoryNice = elem.ChangeToInventory.Fmt
-				c.ChangeLiabilities = elem.ChangeToLiabilities.Raw
-				c.ChangeLiabilitiesNice = elem.ChangeToLiabilities.Fmt
-				c.ChangeNetIncome = elem.ChangeToNetincome.Raw
-				c.ChangeNetIncomeNice = elem.ChangeToNetincome.Fmt
-				c.Depreciation = elem.Depreciation.Raw
-				c.DepreciationNice = elem.Depreciation.Fmt
-				c.EffectExchangeRate = elem.EffectOfExchangeRate.Raw
-				c.EffectExchangeRateNice = elem.EffectOfExchangeRate.Fmt
-				c.EndDate = elem.EndDate.Fmt
-				c.Investments = elem.Investments.Raw
-				c.InvestmentsNice = elem.Investments.Fmt
-				c.NetBorrowings = elem.NetBorrowings.Raw
-				c.NetBorrowingsNice = elem.NetBorrowings.Fmt
-				c.NetIncome = elem.NetIncome.Raw
-				c.NetIncomeNice = elem.NetIncome.Fmt
-				c.OtherCashFinancing = elem.OtherCashflowsFromFinancingActivities.Raw
-				c.OtherCashFinancingNice = elem.OtherCashflowsFromFinancingActivities.Fmt
-				c.OtherCashInvesting = elem.OtherCashflowsFromInvestingActivities.Raw
-				c.OtherCashInvestingNice = elem.OtherCashflowsFromInvestingActivities.Fmt
-				c.RepurchaseStock = elem.RepurchaseOfStock.Raw
-				c.RepurchaseStockNice = elem.RepurchaseOfStock.Fmt
-				c.TotalCashInvesting = elem.TotalCashflowsFromInvestingActivities.Raw
-				c.TotalCashInvestingNice = elem.TotalCashflowsFromInvestingActivities.Fmt
-				c.TotalCashFinancing = elem.TotalCashFromFinancingActivities.Raw
-				c.TotalCashFinancingNice = elem.TotalCashFromFinancingActivities.Fmt
-				c.TotalCashOperating = elem.TotalCashFromOperatingActivities.Raw
-				c.TotalCashOperatingNice = elem.TotalCashFromOperatingActivities.Fmt
-				c.EndDateY = getQuarter(elem.EndDate.Fmt)
+				c.CapEx = elem.CapEx.Value
+				c.CapExNice = elem.CapEx.Display
+				c.ChangeCash = elem.ChangeCash.Value
+				c.ChangeCashNice = elem.ChangeCash.Display
+				c.ChangeAccountReceivables = elem.ChangeAccountReceivables.Value
+				c.ChangeAccountReceivablesNice = elem.ChangeAccountReceivables.Display
+				c.ChangeInventory = elem.ChangeInventory.Value
+				c.ChangeInventoryNice = elem.ChangeInventory.Display
+				c.ChangeLiabilities = elem.ChangeLiabilities.Value
+				c.ChangeLiabilitiesNice = elem.ChangeLiabilities.Display
+				c.ChangeNetIncome = elem.ChangeNetIncome.Value
+				c.ChangeNetIncomeNice = elem.ChangeNetIncome.Display
+				c.Depreciation = elem.Depreciation.Value
+				c.DepreciationNice = elem.Depreciation.Display
+				c.EffectExchangeRate = elem.EffectExchangeRate.Value
+				c.EffectExchangeRateNice = elem.EffectExchangeRate.Display
+				c.EndDate = elem.EndDate
+				c.Investments = elem.Investments.Value
+				c.InvestmentsNice = elem.Investments.Display
+				c.NetBorrowings = elem.NetBorrowings.Value
+				c.NetBorrowingsNice = elem.NetBorrowings.Display
+				c.NetIncome = elem.NetIncome.Value
+				c.NetIncomeNice = elem.NetIncome.Display
+				c.OtherCashFinancing = elem.OtherCashFinancing.Value
+				c.OtherCashFinancingNice = elem.OtherCashFinancing.Display
+				c.OtherCashInvesting = elem.OtherCashInvesting.Value
+				c.OtherCashInvestingNice = elem.OtherCashInvesting.Display
+				c.RepurchaseStock = elem.RepurchaseStock.Value
+				c.RepurchaseStockNice = elem.RepurchaseStock.Display
+				c.TotalCashInvesting = elem.TotalCashInvesting.Value
+				c.TotalCashInvestingNice = elem.TotalCashInvesting.Display
+				c.TotalCashFinancing = elem.TotalCashFinancing.Value
+				c.TotalCashFinancingNice = elem.TotalCashFinancing.Display
+				c.TotalCashOperating = elem.TotalCashOperating.Value
+				c.TotalCashOperatingNice = elem.TotalCashOperating.Display
+				c.EndDateY = getQuarter(elem.EndDate)
 
 				if c.TotalCashOperating != 0 {
 					s.CashFlowHQ = append(s.CashFlowHQ, c)
@@ -890,64 +790,64 @@ func insertStockDatabyDate(cy *yahoodata.YahooData, s *Stock, t string) {
 			}
 		}
 	case "Income":
-		for i := len(cy.QuoteSummary.Result[0].IncomeStatementHistory.IncomeStatementHistory) - 1; i >= 0; i-- {
-			elem := cy.QuoteSummary.Result[0].IncomeStatementHistory.IncomeStatementHistory[i]
+		for i := len(f.Income) - 1; i >= 0; i-- {
+			elem := f.Income[i]
 			found := false
 			for i, elemdb := range s.IncomeH {
 				s.IncomeH[i].EndDateY = getYear(elemdb.EndDate)
-				if elem.EndDate.Fmt == elemdb.EndDate {
+				if elem.EndDate == elemdb.EndDate {
 					found = true
 					break
 				}
 			}
 			if !found {
 				var c incomeH
-				c.TotalRevenue = elem.TotalRevenue.Raw
-				c.TotalRevenueNice = elem.TotalRevenue.Fmt
-				c.CostOfRevenue = elem.CostOfRevenue.Raw
-				c.CostOfRevenueNice = elem.CostOfRevenue.Fmt
-				c.GrossProfit = elem.GrossProfit.Raw
-				c.GrossProfitNice = elem.GrossProfit.Fmt
-				c.ResearchDevelopment = elem.ResearchDevelopment.Raw
-				c.ResearchDevelopmentNice = elem.ResearchDevelopment.Fmt
-				c.SellingGeneralAdministrative = elem.SellingGeneralAdministrative.Raw
-				c.SellingGeneralAdministrativeNice = elem.SellingGeneralAdministrative.Fmt
-				c.NonRecurring = elem.NonRecurring.Raw
-				c.NonRecurringNice = elem.NonRecurring.Fmt
-				c.OtherOperatingExpenses = elem.OtherOperatingExpenses.Raw
-				c.OtherOperatingExpensesNice = elem.OtherOperatingExpenses.Fmt
-				c.TotalOperatingExpenses = elem.TotalOperatingExpenses.Raw
-				c.TotalOperatingExpensesNice = elem.TotalOperatingExpenses.Fmt
-				c.EndDate = elem.EndDate.Fmt
-				c.OperatingIncome = elem.OperatingIncome.Raw
-				c.OperatingIncomeNice = elem.OperatingIncome.Fmt
-				c.TotalOtherIncomeExpenseNet = elem.TotalOtherIncomeExpenseNet.Raw
-				c.TotalOtherIncomeExpenseNetNice = elem.TotalOtherIncomeExpenseNet.Fmt
-				c.Ebit = elem.Ebit.Raw
-				c.EbitNice = elem.Ebit.Fmt
-				c.InterestExpense = elem.InterestExpense.Raw
-				c.InterestExpenseNice = elem.InterestExpense.Fmt
-				c.IncomeBeforeTax = elem.IncomeBeforeTax.Raw
-				c.IncomeBeforeTaxNice = elem.IncomeBeforeTax.Fmt
-				c.IncomeTaxExpense = elem.IncomeTaxExpense.Raw
-				c.IncomeTaxExpenseNice = elem.IncomeTaxExpense.Fmt
-				c.MinorityInterest = elem.MinorityInterest.Raw
-				c.MinorityInterestNice = elem.MinorityInterest.Fmt
-				c.NetIncomeFromContinuingOps = elem.NetIncomeFromContinuingOps.Raw
-				c.NetIncomeFromContinuingOpsNice = elem.NetIncomeFromContinuingOps.Fmt
-				c.DiscontinuedOperations = elem.DiscontinuedOperations.Raw
-				c.DiscontinuedOperationsNice = elem.DiscontinuedOperations.Fmt
-				c.ExtraordinaryItems = elem.ExtraordinaryItems.Raw
-				c.ExtraordinaryItemsNice = elem.ExtraordinaryItems.Fmt
-				c.EffectOfAccountingCharges = elem.EffectOfAccountingCharges.Raw
-				c.EffectOfAccountingChargesNice = elem.EffectOfAccountingCharges.Fmt
-				c.OtherItems = elem.OtherItems.Raw
-				c.OtherItemsNice = elem.OtherItems.Fmt
-				c.NetIncome = elem.NetIncome.Raw
-				c.NetIncomeNice = elem.NetIncome.Fmt
-				c.NetIncomeCommonShares = elem.NetIncomeApplicableToCommonShares.Raw
-				c.NetIncomeCommonSharesNice = elem.NetIncomeApplicableToCommonShares.Fmt
-				c.EndDateY = getYear(elem.EndDate.Fmt)
+				c.TotalRevenue = elem.TotalRevenue.Value
+				c.TotalRevenueNice = elem.TotalRevenue.Display
+				c.CostOfRevenue = elem.CostOfRevenue.Value
+				c.CostOfRevenueNice = elem.CostOfRevenue.Display
+				c.GrossProfit = elem.GrossProfit.Value
+				c.GrossProfitNice = elem.GrossProfit.Display
+				c.ResearchDevelopment = elem.ResearchDevelopment.Value
+				c.ResearchDevelopmentNice = elem.ResearchDevelopment.Display
+				c.SellingGeneralAdministrative = elem.SellingGeneralAdministrative.Value
+				c.SellingGeneralAdministrativeNice = elem.SellingGeneralAdministrative.Display
+				c.NonRecurring = elem.NonRecurring.Value
+				c.NonRecurringNice = elem.NonRecurring.Display
+				c.OtherOperatingExpenses = elem.OtherOperatingExpenses.Value
+				c.OtherOperatingExpensesNice = elem.OtherOperatingExpenses.Display
+				c.TotalOperatingExpenses = elem.TotalOperatingExpenses.Value
+				c.TotalOperatingExpensesNice = elem.TotalOperatingExpenses.Display
+				c.EndDate = elem.EndDate
+				c.OperatingIncome = elem.OperatingIncome.Value
+				c.OperatingIncomeNice = elem.OperatingIncome.Display
+				c.TotalOtherIncomeExpenseNet = elem.TotalOtherIncomeExpenseNet.Value
+				c.TotalOtherIncomeExpenseNetNice = elem.TotalOtherIncomeExpenseNet.Display
+				c.Ebit = elem.Ebit.Value
+				c.EbitNice = elem.Ebit.Display
+				c.InterestExpense = elem.InterestExpense.Value
+				c.InterestExpenseNice = elem.InterestExpense.Display
+				c.IncomeBeforeTax = elem.IncomeBeforeTax.Value
+				c.IncomeBeforeTaxNice = elem.IncomeBeforeTax.Display
+				c.IncomeTaxExpense = elem.IncomeTaxExpense.Value
+				c.IncomeTaxExpenseNice = elem.IncomeTaxExpense.Display
+				c.MinorityInterest = elem.MinorityInterest.Value
+				c.MinorityInterestNice = elem.MinorityInterest.Display
+				c.NetIncomeFromContinuingOps = elem.NetIncomeFromContinuingOps.Value
+				c.NetIncomeFromContinuingOpsNice = elem.NetIncomeFromContinuingOps.Display
+				c.DiscontinuedOperations = elem.DiscontinuedOperations.Value
+				c.DiscontinuedOperationsNice = elem.DiscontinuedOperations.Display
+				c.ExtraordinaryItems = elem.ExtraordinaryItems.Value
+				c.ExtraordinaryItemsNice = elem.ExtraordinaryItems.Display
+				c.EffectOfAccountingCharges = elem.EffectOfAccountingCharges.Value
+				c.EffectOfAccountingChargesNice = elem.EffectOfAccountingCharges.Display
+				c.OtherItems = elem.OtherItems.Value
+				c.OtherItemsNice = elem.OtherItems.Display
+				c.NetIncome = elem.NetIncome.Value
+				c.NetIncomeNice = elem.NetIncome.Display
+				c.NetIncomeCommonShares = elem.NetIncomeCommonShares.Value
+				c.NetIncomeCommonSharesNice = elem.NetIncomeCommonShares.Display
+				c.EndDateY = getYear(elem.EndDate)
 
 				if c.NetIncome != 0 {
 					s.IncomeH = append(s.IncomeH, c)
@@ -955,64 +855,64 @@ func insertStockDatabyDate(cy *yahoodata.YahooData, s *Stock, t string) {
 			}
 		}
 	case "IncomeQ":
-		for i := len(cy.QuoteSummary.Result[0].IncomeStatementHistoryQuarterly.IncomeStatementHistory) - 1; i >= 0; i-- {
-			elem := cy.QuoteSummary.Result[0].IncomeStatementHistoryQuarterly.IncomeStatementHistory[i]
+		for i := len(f.IncomeQuarterly) - 1; i >= 0; i-- {
+			elem := f.IncomeQuarterly[i]
 			found := false
 			for i, elemdb := range s.IncomeHQ {
 				s.IncomeHQ[i].EndDateY = getQuarter(elemdb.EndDate)
-				if elem.EndDate.Fmt == elemdb.EndDate {
+				if elem.EndDate == elemdb.EndDate {
 					found = true
 					break
 				}
 			}
 			if !found {
 				var c incomeH
-				c.TotalRevenue = elem.TotalRevenue.Raw
-				c.TotalRevenueNice = elem.TotalRevenue.Fmt
-				c.CostOfRevenue = elem.CostOfRevenue.Raw
-				c.CostOfRevenueNice = elem.CostOfRevenue.Fmt
-				c.GrossProfit = elem.GrossProfit.Raw
-				c.GrossProfitNice = elem.GrossProfit.Fmt
-				c.ResearchDevelopment = elem.ResearchDevelopment.Raw
-				c.ResearchDevelopmentNice = elem.ResearchDevelopment.Fmt
-				c.SellingGeneralAdministrative = elem.SellingGeneralAdministrative.Raw
-				c.SellingGeneralAdministrativeNice = elem.SellingGeneralAdministrative.Fmt
-				c.NonRecurring = elem.NonRecurring.Raw
-				c.NonRecurringNice = elem.NonRecurring.Fmt
-				c.OtherOperatingExpenses = elem.OtherOperatingExpenses.Raw
-				c.OtherOperatingExpensesNice = elem.OtherOperatingExpenses.Fmt
-				c.TotalOperatingExpenses = elem.TotalOperatingExpenses.Raw
-				c.TotalOperatingExpensesNice = elem.TotalOperatingExpenses.Fmt
-				c.EndDate = elem.EndDate.Fmt
-				c.OperatingIncome = elem.OperatingIncome.Raw
-				c.OperatingIncomeNice = elem.OperatingIncome.Fmt
-				c.TotalOtherIncomeExpenseNet = elem.TotalOtherIncomeExpenseNet.Raw
-				c.TotalOtherIncomeExpenseNetNice = elem.TotalOtherIncomeExpenseNet.Fmt
-				c.Ebit = elem.Ebit.Raw
-				c.EbitNice = elem.Ebit.Fmt
-				c.InterestExpense = elem.InterestExpense.Raw
-				c.InterestExpenseNice = elem.InterestExpense.Fmt
-				c.IncomeBeforeTax = elem.IncomeBeforeTax.Raw
-				c.IncomeBeforeTaxNice = elem.IncomeBeforeTax.Fmt
-				c.IncomeTaxExpense = elem.IncomeTaxExpense.Raw
-				c.IncomeTaxExpenseNice = elem.IncomeTaxExpense.Fmt
-				c.MinorityInterest = elem.MinorityInterest.Raw
-				c.MinorityInterestNice = elem.MinorityInterest.Fmt
-				c.NetIncomeFromContinuingOps = elem.NetIncomeFromContinuingOps.Raw
-				c.NetIncomeFromContinuingOpsNice = elem.NetIncomeFromContinuingOps.Fmt
-				c.DiscontinuedOperations = elem.DiscontinuedOperations.Raw
-				c.DiscontinuedOperationsNice = elem.DiscontinuedOperations.Fmt
-				c.ExtraordinaryItems = elem.ExtraordinaryItems.Raw
-				c.ExtraordinaryItemsNice = elem.ExtraordinaryItems.Fmt
-				c.EffectOfAccountingCharges = elem.EffectOfAccountingCharges.Raw
-				c.EffectOfAccountingChargesNice = elem.EffectOfAccountingCharges.Fmt
-				c.OtherItems = elem.OtherItems.Raw
-				c.OtherItemsNice = elem.OtherItems.Fmt
-				c.NetIncome = elem.NetIncome.Raw
-				c.NetIncomeNice = elem.NetIncome.Fmt
-				c.NetIncomeCommonShares = elem.NetIncomeApplicableToCommonShares.Raw
-				c.NetIncomeCommonSharesNice = elem.NetIncomeApplicableToCommonShares.Fmt
-				c.EndDateY = getQuarter(elem.EndDate.Fmt)
+				c.TotalRevenue = elem.TotalRevenue.Value
+				c.TotalRevenueNice = elem.TotalRevenue.Display
+				c.CostOfRevenue = elem.CostOfRevenue.Value
+				c.CostOfRevenueNice = elem.CostOfRevenue.Display
+				c.GrossProfit = elem.GrossProfit.Value
+				c.GrossProfitNice = elem.GrossProfit.Display
+				c.ResearchDevelopment = elem.ResearchDevelopment.Value
+				c.ResearchDevelopmentNice = elem.ResearchDevelopment.Display
+				c.SellingGeneralAdministrative = elem.SellingGeneralAdministrative.Value
+				c.SellingGeneralAdministrativeNice = elem.SellingGeneralAdministrative.Display
+				c.NonRecurring = elem.NonRecurring.Value
+				c.NonRecurringNice = elem.NonRecurring.Display
+				c.OtherOperatingExpenses = elem.OtherOperatingExpenses.Value
+				c.OtherOperatingExpensesNice = elem.OtherOperatingExpenses.Display
+				c.TotalOperatingExpenses = elem.TotalOperatingExpenses.Value
+				c.TotalOperatingExpensesNice = elem.TotalOperatingExpenses.Display
+				c.EndDate = elem.EndDate
+				c.OperatingIncome = elem.OperatingIncome.Value
+				c.OperatingIncomeNice = elem.OperatingIncome.Display
+				c.TotalOtherIncomeExpenseNet = elem.TotalOtherIncomeExpenseNet.Value
+				c.TotalOtherIncomeExpenseNetNice = elem.TotalOtherIncomeExpenseNet.Display
+				c.Ebit = elem.Ebit.Value
+				c.EbitNice = elem.Ebit.Display
+				c.InterestExpense = elem.InterestExpense.Value
+				c.InterestExpenseNice = elem.InterestExpense.Display
+				c.IncomeBeforeTax = elem.IncomeBeforeTax.Value
+				c.IncomeBeforeTaxNice = elem.IncomeBeforeTax.Display
+				c.IncomeTaxExpense = elem.IncomeTaxExpense.Value
+				c.IncomeTaxExpenseNice = elem.IncomeTaxExpense.Display
+				c.MinorityInterest = elem.MinorityInterest.Value
+				c.MinorityInterestNice = elem.MinorityInterest.Display
+				c.NetIncomeFromContinuingOps = elem.NetIncomeFromContinuingOps.Value
+				c.NetIncomeFromContinuingOpsNice = elem.NetIncomeFromContinuingOps.Display
+				c.DiscontinuedOperations = elem.DiscontinuedOperations.Value
+				c.DiscontinuedOperationsNice = elem.DiscontinuedOperations.Display
+				c.ExtraordinaryItems = elem.ExtraordinaryItems.Value
+				c.ExtraordinaryItemsNice = elem.ExtraordinaryItems.Display
+				c.EffectOfAccountingCharges = elem.EffectOfAccountingCharges.Value
+				c.EffectOfAccountingChargesNice = elem.EffectOfAccountingCharges.Display
+				c.OtherItems = elem.OtherItems.Value
+				c.OtherItemsNice = elem.OtherItems.Display
+				c.NetIncome = elem.NetIncome.Value
+				c.NetIncomeNice = elem.NetIncome.Display
+				c.NetIncomeCommonShares = elem.NetIncomeCommonShares.Value
+				c.NetIncomeCommonSharesNice = elem.NetIncomeCommonShares.Display
+				c.EndDateY = getQuarter(elem.EndDate)
 
 				if c.NetIncome != 0 {
 					s.IncomeHQ = append(s.IncomeHQ, c)
@@ -1020,66 +920,66 @@ func insertStockDatabyDate(cy *yahoodata.YahooData, s *Stock, t string) {
 			}
 		}
 	case "Balance":
-		for i := len(cy.QuoteSummary.Result[0].BalanceSheetHistory.BalanceSheetStatements) - 1; i >= 0; i-- {
-			elem := cy.QuoteSummary.Result[0].BalanceSheetHistory.BalanceSheetStatements[i]
+		for i := len(f.Balance) - 1; i >= 0; i-- {
+			elem := f.Balance[i]
 			found := false
 			for i, elemdb := range s.BalanceH {
 				s.BalanceH[i].EndDateY = getYear(elemdb.EndDate)
-				if elem.EndDate.Fmt == elemdb.EndDate {
+				if elem.EndDate == elemdb.EndDate {
 					found = true
 					break
 				}
 			}
 			if !found {
 				var c balanceH
-				c.Cash = elem.Cash.Raw
-				c.CashNice = elem.Cash.Fmt
-				c.ShortTermInvestments = elem.ShortTermInvestments.Raw
-				c.ShortTermInvestmentsNice = elem.ShortTermInvestments.Fmt
-				c.NetReceivables = elem.NetReceivables.Raw
-				c.NetReceivablesNice = elem.NetReceivables.Fmt
-				c.Inventory = elem.Inventory.Raw
-				c.InventoryNice = elem.Inventory.Fmt
-				c.OtherCurrentAssets = elem.OtherCurrentAssets.Raw
-				c.OtherCurrentAssetsNice = elem.OtherCurrentAssets.Fmt
-				c.TotalCurrentAssets = elem.TotalCurrentAssets.Raw
-				c.TotalCurrentAssetsNice = elem.TotalCurrentAssets.Fmt
-				c.LongTermInvestments = elem.LongTermInvestments.Raw
-				c.LongTermInvestmentsNice = elem.LongTermInvestments.Fmt
-				c.PropertyPlantEquipment = elem.PropertyPlantEquipment.Raw
-				c.PropertyPlantEquipmentNice = elem.PropertyPlantEquipment.Fmt
-				c.EndDate = elem.EndDate.Fmt
-				c.OtherAssets = elem.OtherAssets.Raw
-				c.OtherAssetsNice = elem.OtherAssets.Fmt
-				c.TotalAssets = elem.TotalAssets.Raw
-				c.TotalAssetsNice = elem.TotalAssets.Fmt
-				c.AccountsPayable = elem.AccountsPayable.Raw
-				c.AccountsPayableNice = elem.AccountsPayable.Fmt
-				c.ShortLongTermDebt = elem.ShortLongTermDebt.Raw
-				c.ShortLongTermDebtNice = elem.ShortLongTermDebt.Fmt
-				c.OtherCurrentLiab = elem.OtherCurrentLiab.Raw
-				c.OtherCurrentLiabNice = elem.OtherCurrentLiab.Fmt
-				c.LongTermDebt = elem.LongTermDebt.Raw
-				c.LongTermDebtNice = elem.LongTermDebt.Fmt
-				c.OtherLiab = elem.OtherLiab.Raw
-				c.OtherLiabNice = elem.OtherLiab.Fmt
-				c.TotalCurrentLiabilities = elem.TotalCurrentLiabilities.Raw
-				c.TotalCurrentLiabilitiesNice = elem.TotalCurrentLiabilities.Fmt
-				c.TotalLiab = elem.TotalLiab.Raw
-				c.TotalLiabNice = elem.TotalLiab.Fmt
-				c.CommonStock = elem.CommonStock.Raw
-				c.CommonStockNice = elem.CommonStock.Fmt
-				c.RetainedEarnings = elem.RetainedEarnings.Raw
-				c.RetainedEarningsNice = elem.RetainedEarnings.Fmt
-				c.TreasuryStock = elem.TreasuryStock.Raw
-				c.TreasuryStockNice = elem.TreasuryStock.Fmt
-				c.OtherStockholderEquity = elem.OtherStockholderEquity.Raw
-				c.OtherStockholderEquityNice = elem.OtherStockholderEquity.Fmt
-				c.TotalStockholderEquity = elem.TotalStockholderEquity.Raw
-				c.TotalStockholderEquityNice = elem.TotalStockholderEquity.Fmt
-				c.NetTangibleAssets = elem.NetTangibleAssets.Raw
-				c.NetTangibleAssetsNice = elem.NetTangibleAssets.Fmt
-				c.EndDateY = getYear(elem.EndDate.Fmt)
+				c.Cash = elem.Cash.Value
+				c.CashNice = elem.Cash.Display
+				c.ShortTermInvestments = elem.ShortTermInvestments.Value
+				c.ShortTermInvestmentsNice = elem.ShortTermInvestments.Display
+				c.NetReceivables = elem.NetReceivables.Value
+				c.NetReceivablesNice = elem.NetReceivables.Display
+				c.Inventory = elem.Inventory.Value
+				c.InventoryNice = elem.Inventory.Display
+				c.OtherCurrentAssets = elem.OtherCurrentAssets.Value
+				c.OtherCurrentAssetsNice = elem.OtherCurrentAssets.Display
+				c.TotalCurrentAssets = elem.TotalCurrentAssets.Value
+				c.TotalCurrentAssetsNice = elem.TotalCurrentAssets.Display
+				c.LongTermInvestments = elem.LongTermInvestments.Value
+				c.LongTermInvestmentsNice = elem.LongTermInvestments.Display
+				c.PropertyPlantEquipment = elem.PropertyPlantEquipment.Value
+				c.PropertyPlantEquipmentNice = elem.PropertyPlantEquipment.Display
+				c.EndDate = elem.EndDate
+				c.OtherAssets = elem.OtherAssets.Value
+				c.OtherAssetsNice = elem.OtherAssets.Display
+				c.TotalAssets = elem.TotalAssets.Value
+				c.TotalAssetsNice = elem.TotalAssets.Display
+				c.AccountsPayable = elem.AccountsPayable.Value
+				c.AccountsPayableNice = elem.AccountsPayable.Display
+				c.ShortLongTermDebt = elem.ShortLongTermDebt.Value
+				c.ShortLongTermDebtNice = elem.ShortLongTermDebt.Display
+				c.OtherCurrentLiab = elem.OtherCurrentLiab.Value
+				c.OtherCurrentLiabNice = elem.OtherCurrentLiab.Display
+				c.LongTermDebt = elem.LongTermDebt.Value
+				c.LongTermDebtNice = elem.LongTermDebt.Display
+				c.OtherLiab = elem.OtherLiab.Value
+				c.OtherLiabNice = elem.OtherLiab.Display
+				c.TotalCurrentLiabilities = elem.TotalCurrentLiabilities.Value
+				c.TotalCurrentLiabilitiesNice = elem.TotalCurrentLiabilities.Display
+				c.TotalLiab = elem.TotalLiab.Value
+				c.TotalLiabNice = elem.TotalLiab.Display
+				c.CommonStock = elem.CommonStock.Value
+				c.CommonStockNice = elem.CommonStock.Display
+				c.RetainedEarnings = elem.RetainedEarnings.Value
+				c.RetainedEarningsNice = elem.RetainedEarnings.Display
+				c.TreasuryStock = elem.TreasuryStock.Value
+				c.TreasuryStockNice = elem.TreasuryStock.Display
+				c.OtherStockholderEquity = elem.OtherStockholderEquity.Value
+				c.OtherStockholderEquityNice = elem.OtherStockholderEquity.Display
+				c.TotalStockholderEquity = elem.TotalStockholderEquity.Value
+				c.TotalStockholderEquityNice = elem.TotalStockholderEquity.Display
+				c.NetTangibleAssets = elem.NetTangibleAssets.Value
+				c.NetTangibleAssetsNice = elem.NetTangibleAssets.Display
+				c.EndDateY = getYear(elem.EndDate)
 
 				if c.Cash != 0 {
 					s.BalanceH = append(s.BalanceH, c)
@@ -1087,66 +987,66 @@ func insertStockDatabyDate(cy *yahoodata.YahooData, s *Stock, t string) {
 			}
 		}
 	case "BalanceQ":
-		for i := len(cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements) - 1; i >= 0; i-- {
-			elem := cy.QuoteSummary.Result[0].BalanceSheetHistoryQuarterly.BalanceSheetStatements[i]
+		for i := len(f.BalanceQuarterly) - 1; i >= 0; i-- {
+			elem := f.BalanceQuarterly[i]
 			found := false
 			for i, elemdb := range s.BalanceHQ {
 				s.BalanceHQ[i].EndDateY = getQuarter(elemdb.EndDate)
-				if elem.EndDate.Fmt == elemdb.EndDate {
+				if elem.EndDate == elemdb.EndDate {
 					found = true
 					break
 				}
 			}
 			if !found {
 				var c balanceH
-				c.Cash = elem.Cash.Raw
-				c.CashNice = elem.Cash.Fmt
-				c.ShortTermInvestments = elem.ShortTermInvestments.Raw
-				c.ShortTermInvestmentsNice = elem.ShortTermInvestments.Fmt
-				c.NetReceivables = elem.NetReceivables.Raw
-				c.NetReceivablesNice = elem.NetReceivables.Fmt
-				c.Inventory = elem.Inventory.Raw
-				c.InventoryNice = elem.Inventory.Fmt
-				c.OtherCurrentAssets = elem.OtherCurrentAssets.Raw
-				c.OtherCurrentAssetsNice = elem.OtherCurrentAssets.Fmt
-				c.TotalCurrentAssets = elem.TotalCurrentAssets.Raw
-				c.TotalCurrentAssetsNice = elem.TotalCurrentAssets.Fmt
-				c.LongTermInvestments = elem.LongTermInvestments.Raw
-				c.LongTermInvestmentsNice = elem.LongTermInvestments.Fmt
-				c.PropertyPlantEquipment = elem.PropertyPlantEquipment.Raw
-				c.PropertyPlantEquipmentNice = elem.PropertyPlantEquipment.Fmt
-				c.EndDate = elem.EndDate.Fmt
-				c.OtherAssets = elem.OtherAssets.Raw
-				c.OtherAssetsNice = elem.OtherAssets.Fmt
-				c.TotalAssets = elem.TotalAssets.Raw
-				c.TotalAssetsNice = elem.TotalAssets.Fmt
-				c.AccountsPayable = elem.AccountsPayable.Raw
-				c.AccountsPayableNice = elem.AccountsPayable.Fmt
-				c.ShortLongTermDebt = elem.ShortLongTermDebt.Raw
-				c.ShortLongTermDebtNice = elem.ShortLongTermDebt.Fmt
-				c.OtherCurrentLiab = elem.OtherCurrentLiab.Raw
-				c.OtherCurrentLiabNice = elem.OtherCurrentLiab.Fmt
-				c.LongTermDebt = elem.LongTermDebt.Raw
-				c.LongTermDebtNice = elem.LongTermDebt.Fmt
-				c.OtherLiab = elem.OtherLiab.Raw
-				c.OtherLiabNice = elem.OtherLiab.Fmt
-				c.TotalCurrentLiabilities = elem.TotalCurrentLiabilities.Raw
-				c.TotalCurrentLiabilitiesNice = elem.TotalCurrentLiabilities.Fmt
-				c.TotalLiab = elem.TotalLiab.Raw
-				c.TotalLiabNice = elem.TotalLiab.Fmt
-				c.CommonStock = elem.CommonStock.Raw
-				c.CommonStockNice = elem.CommonStock.Fmt
-				c.RetainedEarnings = elem.RetainedEarnings.Raw
-				c.RetainedEarningsNice = elem.RetainedEarnings.Fmt
-				c.TreasuryStock = elem.TreasuryStock.Raw
-				c.TreasuryStockNice = elem.TreasuryStock.Fmt
-				c.OtherStockholderEquity = elem.OtherStockholderEquity.Raw
-				c.OtherStockholderEquityNice = elem.OtherStockholderEquity.Fmt
-				c.TotalStockholderEquity = elem.TotalStockholderEquity.Raw
-				c.TotalStockholderEquityNice = elem.TotalStockholderEquity.Fmt
-				c.NetTangibleAssets = elem.NetTangibleAssets.Raw
-				c.NetTangibleAssetsNice = elem.NetTangibleAssets.Fmt
-				c.EndDateY = getQuarter(elem.EndDate.Fmt)
+				c.Cash = elem.Cash.Value
+				c.CashNice = elem.Cash.Display
+				c.ShortTermInvestments = elem.ShortTermInvestments.Value
+				c.ShortTermInvestmentsNice = elem.ShortTermInvestments.Display
+				c.NetReceivables = elem.NetReceivables.Value
+				c.NetReceivablesNice = elem.NetReceivables.Display
+				c.Inventory = elem.Inventory.Value
+				c.InventoryNice = elem.Inventory.Display
+				c.OtherCurrentAssets = elem.OtherCurrentAssets.Value
+				c.OtherCurrentAssetsNice = elem.OtherCurrentAssets.Display
+				c.TotalCurrentAssets = elem.TotalCurrentAssets.Value
+				c.TotalCurrentAssetsNice = elem.TotalCurrentAssets.Display
+				c.LongTermInvestments = elem.LongTermInvestments.Value
+				c.LongTermInvestmentsNice = elem.LongTermInvestments.Display
+				c.PropertyPlantEquipment = elem.PropertyPlantEquipment.Value
+				c.PropertyPlantEquipmentNice = elem.PropertyPlantEquipment.Display
+				c.EndDate = elem.EndDate
+				c.OtherAssets = elem.OtherAssets.Value
+				c.OtherAssetsNice = elem.OtherAssets.Display
+				c.TotalAssets = elem.TotalAssets.Value
+				c.TotalAssetsNice = elem.TotalAssets.Display
+				c.AccountsPayable = elem.AccountsPayable.Value
+				c.AccountsPayableNice = elem.AccountsPayable.Display
+				c.ShortLongTermDebt = elem.ShortLongTermDebt.Value
+				c.ShortLongTermDebtNice = elem.ShortLongTermDebt.Display
+				c.OtherCurrentLiab = elem.OtherCurrentLiab.Value
+				c.OtherCurrentLiabNice = elem.OtherCurrentLiab.Display
+				c.LongTermDebt = elem.LongTermDebt.Value
+				c.LongTermDebtNice = elem.LongTermDebt.Display
+				c.OtherLiab = elem.OtherLiab.Value
+				c.OtherLiabNice = elem.OtherLiab.Display
+				c.TotalCurrentLiabilities = elem.TotalCurrentLiabilities.Value
+				c.TotalCurrentLiabilitiesNice = elem.TotalCurrentLiabilities.Display
+				c.TotalLiab = elem.TotalLiab.Value
+				c.TotalLiabNice = elem.TotalLiab.Display
+				c.CommonStock = elem.CommonStock.Value
+				c.CommonStockNice = elem.CommonStock.Display
+				c.RetainedEarnings = elem.RetainedEarnings.Value
+				c.RetainedEarningsNice = elem.RetainedEarnings.Display
+				c.TreasuryStock = elem.TreasuryStock.Value
+				c.TreasuryStockNice = elem.TreasuryStock.Display
+				c.OtherStockholderEquity = elem.OtherStockholderEquity.Value
+				c.OtherStockholderEquityNice = elem.OtherStockholderEquity.Display
+				c.TotalStockholderEquity = elem.TotalStockholderEquity.Value
+				c.TotalStockholderEquityNice = elem.TotalStockholderEquity.Display
+				c.NetTangibleAssets = elem.NetTangibleAssets.Value
+				c.NetTangibleAssetsNice = elem.NetTangibleAssets.Display
+				c.EndDateY = getQuarter(elem.EndDate)
 
 				if c.Cash != 0 {
 					s.BalanceHQ = append(s.BalanceHQ, c)
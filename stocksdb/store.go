@@ -0,0 +1,81 @@
+package stocksdb
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Store holds the MongoDB client shared by all requests. Create it once at
+// startup with NewStore and Close it on shutdown.
+type Store struct {
+	client *mongo.Client
+	db     *mongo.Database
+
+	// setupMu guards the flags of the collection and index created on first
+	// use. A failed creation is tried again on the next call.
+	setupMu                sync.Mutex
+	snapshotsCollReady     bool
+	rawResponsesIndexReady bool
+}
+
+// NewStore connects to the server with a pool of at most maxPoolSize
+// connections. A maxPoolSize of 0 keeps the driver default. The monitors
+// are told about every command sent to the server.
+func NewStore(ctx context.Context, dbServer, dbPort, dbUser, dbPass string, maxPoolSize uint64, monitors ...*event.CommandMonitor) (*Store, error) {
+	mongoString := "mongodb://" + dbUser + ":" + dbPass + "@" + dbServer + ":" + dbPort
+	opts := options.Client().ApplyURI(mongoString)
+	if maxPoolSize > 0 {
+		opts.SetMaxPoolSize(maxPoolSize)
+	}
+	if len(monitors) > 0 {
+		opts.SetMonitor(combineMonitors(monitors))
+	}
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{client: client, db: client.Database(stocksDataBase)}, nil
+}
+
+func combineMonitors(monitors []*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
+
+// Ping checks that the primary can be reached with the configured
+// credentials.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, readpref.Primary())
+}
+
+func (s *Store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"stocks/stocksdb"
+	"stocks/yahoodata"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+const serviceName = "stocks-import-api"
+
+var tracer = otel.Tracer("stocks")
+
+// setupTracing installs the tracer provider and W3C trace context
+// propagation and instruments the Yahoo and competitors HTTP clients. The
+// returned function flushes pending spans.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	client := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	yahoodata.HTTPClient = client
+	stocksdb.CompetitorsClient = client
+
+	// Without tracing.exporter, traces are sent with OTLP over HTTP if an
+	// OTLP endpoint is set and dropped otherwise. The OTLP exporter reads its
+	// other settings from the standard OTEL_EXPORTER_OTLP_* variables.
+	exporterName := conf.Tracing.Exporter
+	if exporterName == "" {
+		exporterName = "none"
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			exporterName = "otlp"
+		}
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "console", "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	// Values from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win.
+	res, err = resource.Merge(res, resource.Environment())
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,42 @@
+package yahoodata
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"stocks/marketdata"
+	"strings"
+)
+
+// FileProvider serves quoteSummary responses from <TICKER>.json files in Dir
+// instead of calling yfapi.net. The files have the same shape NewData
+// unmarshals, so a saved API response can be used as is.
+type FileProvider struct {
+	Dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir}
+}
+
+func (p *FileProvider) Name() string {
+	return "yahoo"
+}
+
+func (p *FileProvider) Fetch(ctx context.Context, ticker string) (*marketdata.Fundamentals, error) {
+	if ticker == "" || strings.ContainsAny(ticker, `/\`) || strings.HasPrefix(ticker, ".") {
+		return nil, marketdata.ErrNotFound
+	}
+
+	body, err := ioutil.ReadFile(filepath.Join(p.Dir, strings.ToUpper(ticker)+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, marketdata.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseFundamentals(body)
+}
@@ -0,0 +1,316 @@
+package yahoodata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"stocks/marketdata"
+)
+
+// Provider fetches from yfapi.net with keys from a pool, moving on to the
+// next key when one runs out of quota.
+type Provider struct {
+	keys marketdata.KeyPool
+}
+
+func NewProvider(keys marketdata.KeyPool) *Provider {
+	return &Provider{keys}
+}
+
+func (p *Provider) Name() string {
+	return "yahoo"
+}
+
+func (p *Provider) Fetch(ctx context.Context, ticker string) (*marketdata.Fundamentals, error) {
+	attempts := 0
+	tried := make(map[string]bool)
+	for {
+		key, err := p.keys.Acquire(ctx)
+		if err != nil {
+			return nil, &marketdata.FetchError{Attempts: attempts, Err: err}
+		}
+		if tried[key.ID] {
+			return nil, &marketdata.FetchError{Attempts: attempts, Err: marketdata.ErrNoKey}
+		}
+		tried[key.ID] = true
+
+		d, err := NewData(ctx, key.Value, ticker)
+		if err != nil {
+			attempts += marketdata.Attempts(err)
+		} else {
+			attempts += d.Attempts
+		}
+
+		if QuotaExceeded(err) {
+			if err := p.keys.Exhausted(ctx, key); err != nil {
+				return nil, &marketdata.FetchError{Attempts: attempts, Err: err}
+			}
+			continue
+		}
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, &marketdata.FetchError{Attempts: attempts, Err: marketdata.ErrNotFound}
+		}
+		if err != nil {
+			return nil, &marketdata.FetchError{Attempts: attempts, Err: err}
+		}
+
+		f, err := d.Fundamentals()
+		if err != nil {
+			return nil, &marketdata.FetchError{Attempts: attempts, Err: err}
+		}
+		f.Attempts = attempts
+		return f, nil
+	}
+}
+
+// ParseFundamentals converts a stored quoteSummary response body.
+func ParseFundamentals(body []byte) (*marketdata.Fundamentals, error) {
+	d, err := ParseData(body)
+	if err != nil {
+		return nil, err
+	}
+	return d.Fundamentals()
+}
+
+type yahooInt struct {
+	Fmt     string `json:"fmt"`
+	LongFmt string `json:"longFmt"`
+	Raw     int64  `json:"raw"`
+}
+
+type yahooFloat struct {
+	Fmt string  `json:"fmt"`
+	Raw float64 `json:"raw"`
+}
+
+func toInt(v yahooInt) marketdata.Int {
+	return marketdata.Int{Value: v.Raw, Display: v.Fmt}
+}
+
+func toFloat(v yahooFloat) marketdata.Float {
+	return marketdata.Float{Value: v.Raw, Display: v.Fmt}
+}
+
+// Fundamentals normalizes the response. It returns marketdata.ErrNotFound
+// when Yahoo has no usable profile for the ticker.
+func (d *YahooData) Fundamentals() (*marketdata.Fundamentals, error) {
+	if len(d.QuoteSummary.Result) == 0 || d.QuoteSummary.Result[0].AssetProfile.Country == "" {
+		return nil, marketdata.ErrNotFound
+	}
+	r := d.QuoteSummary.Result[0]
+
+	f := &marketdata.Fundamentals{
+		Symbol:   r.Price.Symbol,
+		Name:     r.Price.ShortName,
+		Exchange: r.Price.ExchangeName,
+		Currency: r.SummaryDetail.Currency,
+		Raw:      d.Raw,
+		Attempts: d.Attempts,
+	}
+
+	f.Profile = marketdata.Profile{
+		Address:   r.AssetProfile.Address1,
+		City:      r.AssetProfile.City,
+		Country:   r.AssetProfile.Country,
+		Industry:  r.AssetProfile.Industry,
+		Sector:    r.AssetProfile.Sector,
+		Employees: r.AssetProfile.FullTimeEmployees,
+	}
+
+	for _, elem := range r.RecommendationTrend.Trend {
+		if elem.Period == "0m" {
+			f.Recommendation = marketdata.Recommendation{
+				StrongBuy:  elem.StrongBuy,
+				Buy:        elem.Buy,
+				Hold:       elem.Hold,
+				Sell:       elem.Sell,
+				StrongSell: elem.StrongSell,
+			}
+			break
+		}
+	}
+
+	ks := r.DefaultKeyStatistics
+	f.KeyStatistics = marketdata.KeyStatistics{
+		Beta:                    toFloat(ks.Beta),
+		EnterpriseValue:         toInt(ks.EnterpriseValue),
+		ForwardPE:               toFloat(ks.ForwardPE),
+		ProfitMargins:           toFloat(ks.ProfitMargins),
+		FloatShares:             toInt(ks.FloatShares),
+		SharesOutstanding:       toInt(ks.SharesOutstanding),
+		SharesShort:             toInt(ks.SharesShort),
+		HeldPercentInsiders:     toFloat(ks.HeldPercentInsiders),
+		HeldPercentInstitutions: toFloat(ks.HeldPercentInstitutions),
+		ShortRatio:              toFloat(ks.ShortRatio),
+		ShortPercentOfFloat:     toFloat(ks.ShortPercentOfFloat),
+		BookValue:               toFloat(ks.BookValue),
+		PriceToBook:             toFloat(ks.PriceToBook),
+		LastFiscalYearEnd:       ks.LastFiscalYearEnd.Fmt,
+		MostRecentQuarter:       ks.MostRecentQuarter.Fmt,
+		NetIncomeToCommon:       toInt(ks.NetIncomeToCommon),
+		TrailingEps:             toFloat(ks.TrailingEps),
+		ForwardEps:              toFloat(ks.ForwardEps),
+		PegRatio:                toFloat(ks.PegRatio),
+		LastSplitFactor:         ks.LastSplitFactor,
+		LastSplitDate:           ks.LastSplitDate.Fmt,
+		EnterpriseToRevenue:     toFloat(ks.EnterpriseToRevenue),
+		EnterpriseToEbitda:      toFloat(ks.EnterpriseToEbitda),
+		WeekChange52:            toFloat(ks.WeekChange52),
+	}
+
+	sd := r.SummaryDetail
+	f.Summary = marketdata.Summary{
+		ExDividendDate: sd.ExDividendDate.Fmt,
+		DividendRate:   toFloat(sd.DividendRate),
+		DividendYield:  toFloat(sd.DividendYield),
+		PayoutRatio:    toFloat(sd.PayoutRatio),
+		TrailingPE:     toFloat(sd.TrailingPE),
+		MarketCap:      toInt(sd.MarketCap),
+	}
+
+	e := r.CalendarEvents.Earnings
+	f.NextEarnings = marketdata.Earnings{
+		EarningsAverage: toFloat(e.EarningsAverage),
+		EarningsLow:     toFloat(e.EarningsLow),
+		EarningsHigh:    toFloat(e.EarningsHigh),
+		RevenueAverage:  toInt(e.RevenueAverage),
+		RevenueLow:      toInt(e.RevenueLow),
+		RevenueHigh:     toInt(e.RevenueHigh),
+	}
+	for _, date := range e.EarningsDate {
+		f.NextEarnings.Dates = append(f.NextEarnings.Dates, date.Fmt)
+	}
+
+	for _, elem := range r.EarningsTrend.Trend {
+		if elem.Period == "+5y" {
+			f.Growth5y = toFloat(elem.Growth)
+		}
+	}
+
+	fd := r.FinancialData
+	f.Financial = marketdata.Financial{
+		CurrentPrice:      toFloat(fd.CurrentPrice),
+		TargetHighPrice:   toFloat(fd.TargetHighPrice),
+		TargetLowPrice:    toFloat(fd.TargetLowPrice),
+		TargetMedianPrice: toFloat(fd.TargetMedianPrice),
+		RecommendationKey: fd.RecommendationKey,
+		TotalCash:         toInt(fd.TotalCash),
+		TotalCashPerShare: toFloat(fd.TotalCashPerShare),
+		Ebitda:            toInt(fd.Ebitda),
+		TotalDebt:         toInt(fd.TotalDebt),
+		QuickRatio:        toFloat(fd.QuickRatio),
+		CurrentRatio:      toFloat(fd.CurrentRatio),
+		TotalRevenue:      toInt(fd.TotalRevenue),
+		RevenuePerShare:   toFloat(fd.RevenuePerShare),
+		ReturnOnAssets:    toFloat(fd.ReturnOnAssets),
+		ReturnOnEquity:    toFloat(fd.ReturnOnEquity),
+		GrossProfits:      toInt(fd.GrossProfits),
+		FreeCashflow:      toInt(fd.FreeCashflow),
+		OperatingCashflow: toInt(fd.OperatingCashflow),
+		GrossMargins:      toFloat(fd.GrossMargins),
+		EbitdaMargins:     toFloat(fd.EbitdaMargins),
+		OperatingMargins:  toFloat(fd.OperatingMargins),
+	}
+
+	f.CashFlow = toCashFlowStatements(r.CashflowStatementHistory)
+	f.CashFlowQuarterly = toCashFlowStatements(r.CashflowStatementHistoryQuarterly)
+	f.Income = toIncomeStatements(r.IncomeStatementHistory)
+	f.IncomeQuarterly = toIncomeStatements(r.IncomeStatementHistoryQuarterly)
+	f.Balance = toBalanceSheets(r.BalanceSheetHistory)
+	f.BalanceQuarterly = toBalanceSheets(r.BalanceSheetHistoryQuarterly)
+
+	return f, nil
+}
+
+func toCashFlowStatements(h yahooDataCashFlowStmH) []marketdata.CashFlowStatement {
+	var statements []marketdata.CashFlowStatement
+	for _, elem := range h.CashflowStatements {
+		statements = append(statements, marketdata.CashFlowStatement{
+			EndDate:                  elem.EndDate.Fmt,
+			CapEx:                    toInt(elem.CapitalExpenditures),
+			ChangeCash:               toInt(elem.ChangeInCash),
+			ChangeAccountReceivables: toInt(elem.ChangeToAccountReceivables),
+			ChangeInventory:          toInt(elem.ChangeToInventory),
+			ChangeLiabilities:        toInt(elem.ChangeToLiabilities),
+			ChangeNetIncome:          toInt(elem.ChangeToNetincome),
+			Depreciation:             toInt(elem.Depreciation),
+			EffectExchangeRate:       toInt(elem.EffectOfExchangeRate),
+			Investments:              toInt(elem.Investments),
+			NetBorrowings:            toInt(elem.NetBorrowings),
+			NetIncome:                toInt(elem.NetIncome),
+			OtherCashFinancing:       toInt(elem.OtherCashflowsFromFinancingActivities),
+			OtherCashInvesting:       toInt(elem.OtherCashflowsFromInvestingActivities),
+			RepurchaseStock:          toInt(elem.RepurchaseOfStock),
+			TotalCashInvesting:       toInt(elem.TotalCashflowsFromInvestingActivities),
+			TotalCashFinancing:       toInt(elem.TotalCashFromFinancingActivities),
+			TotalCashOperating:       toInt(elem.TotalCashFromOperatingActivities),
+		})
+	}
+	return statements
+}
+
+func toIncomeStatements(h yahooDataIncomeStmH) []marketdata.IncomeStatement {
+	var statements []marketdata.IncomeStatement
+	for _, elem := range h.IncomeStatementHistory {
+		statements = append(statements, marketdata.IncomeStatement{
+			EndDate:                      elem.EndDate.Fmt,
+			TotalRevenue:                 toInt(elem.TotalRevenue),
+			CostOfRevenue:                toInt(elem.CostOfRevenue),
+			GrossProfit:                  toInt(elem.GrossProfit),
+			ResearchDevelopment:          toInt(elem.ResearchDevelopment),
+			SellingGeneralAdministrative: toInt(elem.SellingGeneralAdministrative),
+			NonRecurring:                 toInt(elem.NonRecurring),
+			OtherOperatingExpenses:       toInt(elem.OtherOperatingExpenses),
+			TotalOperatingExpenses:       toInt(elem.TotalOperatingExpenses),
+			OperatingIncome:              toInt(elem.OperatingIncome),
+			TotalOtherIncomeExpenseNet:   toInt(elem.TotalOtherIncomeExpenseNet),
+			Ebit:                         toInt(elem.Ebit),
+			InterestExpense:              toInt(elem.InterestExpense),
+			IncomeBeforeTax:              toInt(elem.IncomeBeforeTax),
+			IncomeTaxExpense:             toInt(elem.IncomeTaxExpense),
+			MinorityInterest:             toInt(elem.MinorityInterest),
+			NetIncomeFromContinuingOps:   toInt(elem.NetIncomeFromContinuingOps),
+			DiscontinuedOperations:       toInt(elem.DiscontinuedOperations),
+			ExtraordinaryItems:           toInt(elem.ExtraordinaryItems),
+			EffectOfAccountingCharges:    toInt(elem.EffectOfAccountingCharges),
+			OtherItems:                   toInt(elem.OtherItems),
+			NetIncome:                    toInt(elem.NetIncome),
+			NetIncomeCommonShares:        toInt(elem.NetIncomeApplicableToCommonShares),
+		})
+	}
+	return statements
+}
+
+func toBalanceSheets(h yahooDataBalanceSheetStmH) []marketdata.BalanceSheet {
+	var statements []marketdata.BalanceSheet
+	for _, elem := range h.BalanceSheetStatements {
+		statements = append(statements, marketdata.BalanceSheet{
+			EndDate:                 elem.EndDate.Fmt,
+			Cash:                    toInt(elem.Cash),
+			ShortTermInvestments:    toInt(elem.ShortTermInvestments),
+			NetReceivables:          toInt(elem.NetReceivables),
+			Inventory:               toInt(elem.Inventory),
+			OtherCurrentAssets:      toInt(elem.OtherCurrentAssets),
+			TotalCurrentAssets:      toInt(elem.TotalCurrentAssets),
+			LongTermInvestments:     toInt(elem.LongTermInvestments),
+			PropertyPlantEquipment:  toInt(elem.PropertyPlantEquipment),
+			OtherAssets:             toInt(elem.OtherAssets),
+			TotalAssets:             toInt(elem.TotalAssets),
+			AccountsPayable:         toInt(elem.AccountsPayable),
+			ShortLongTermDebt:       toInt(elem.ShortLongTermDebt),
+			OtherCurrentLiab:        toInt(elem.OtherCurrentLiab),
+			LongTermDebt:            toInt(elem.LongTermDebt),
+			OtherLiab:               toInt(elem.OtherLiab),
+			TotalCurrentLiabilities: toInt(elem.TotalCurrentLiabilities),
+			TotalLiab:               toInt(elem.TotalLiab),
+			CommonStock:             toInt(elem.CommonStock),
+			RetainedEarnings:        toInt(elem.RetainedEarnings),
+			TreasuryStock:           toInt(elem.TreasuryStock),
+			OtherStockholderEquity:  toInt(elem.OtherStockholderEquity),
+			TotalStockholderEquity:  toInt(elem.TotalStockholderEquity),
+			NetTangibleAssets:       toInt(elem.NetTangibleAssets),
+		})
+	}
+	return statements
+}
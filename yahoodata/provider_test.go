@@ -0,0 +1,117 @@
+package yahoodata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"stocks/breaker"
+	"stocks/marketdata"
+	"testing"
+	"time"
+)
+
+const testBody = `{"quoteSummary":{"result":[{"price":{"symbol":"AAPL"},"assetProfile":{"country":"United States"}}]}}`
+
+// testKeys hands out its keys in order and records the exhausted ones.
+type testKeys struct {
+	keys      []string
+	next      int
+	exhausted []string
+}
+
+func (k *testKeys) Acquire(ctx context.Context) (*marketdata.APIKey, error) {
+	if k.next >= len(k.keys) {
+		return nil, marketdata.ErrNoKey
+	}
+	key := k.keys[k.next]
+	k.next++
+	return &marketdata.APIKey{ID: key, Value: key}, nil
+}
+
+func (k *testKeys) Exhausted(ctx context.Context, key *marketdata.APIKey) error {
+	k.exhausted = append(k.exhausted, key.ID)
+	return nil
+}
+
+// serveYahoo points YBASEURL at handler and gives the test its own breaker
+// and a retry policy without delays.
+func serveYahoo(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	prevURL, prevPolicy, prevBreaker := YBASEURL, DefaultRetryPolicy, Breaker
+	YBASEURL = srv.URL + "/<Ticker>"
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3}
+	Breaker = breaker.New("test", 5, time.Minute)
+	t.Cleanup(func() {
+		YBASEURL, DefaultRetryPolicy, Breaker = prevURL, prevPolicy, prevBreaker
+		srv.Close()
+	})
+}
+
+func TestFetchRetriesRateLimit(t *testing.T) {
+	requests := 0
+	serveYahoo(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Too Many Requests"}`))
+			return
+		}
+		w.Write([]byte(testBody))
+	})
+
+	keys := &testKeys{keys: []string{"k1", "k2"}}
+	f, err := NewProvider(keys).Fetch(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", f.Attempts)
+	}
+	if len(keys.exhausted) != 0 || keys.next != 1 {
+		t.Errorf("exhausted %v after %d keys, want the first key kept", keys.exhausted, keys.next)
+	}
+}
+
+func TestFetchFailsOverOnQuota(t *testing.T) {
+	serveYahoo(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") == "k1" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Limit Exceeded"}`))
+			return
+		}
+		w.Write([]byte(testBody))
+	})
+
+	keys := &testKeys{keys: []string{"k1", "k2"}}
+	f, err := NewProvider(keys).Fetch(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Attempts != 2 {
+		t.Errorf("attempts = %d, want one per key", f.Attempts)
+	}
+	if len(keys.exhausted) != 1 || keys.exhausted[0] != "k1" {
+		t.Errorf("exhausted = %v, want [k1]", keys.exhausted)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"", 0, 0},
+		{"7", 7 * time.Second, 7 * time.Second},
+		{"-3", 0, 0},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+	}
+	for _, tt := range tests {
+		if d := retryAfter(tt.header); d < tt.min || d > tt.max {
+			t.Errorf("retryAfter(%q) = %v, want between %v and %v", tt.header, d, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package yahoodata
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how NewData retries a request that failed with a
+// network error, 429 or 5xx. The wait before retry n is BaseDelay*2^(n-1),
+// capped at MaxDelay, with random jitter of up to half of it. A longer
+// Retry-After from Yahoo takes precedence.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	jitter, err := rand.Int(rand.Reader, big.NewInt(int64(half)+1))
+	if err != nil {
+		return d
+	}
+	return d - half + time.Duration(jitter.Int64())
+}
+
+// retryable tells whether another attempt with the same key can succeed.
+// An exhausted quota cannot; Provider moves on to the next key instead.
+func retryable(err error) bool {
+	return unhealthy(err) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// unhealthy tells whether err counts against Breaker. Answers like 404 or
+// an exhausted quota show that Yahoo works.
+func unhealthy(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return (statusErr.StatusCode == http.StatusTooManyRequests && !QuotaExceeded(err)) || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+// A date in the past means no wait.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// wait sleeps for d unless ctx is done first or its deadline would pass
+// before the next attempt could start.
+func wait(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
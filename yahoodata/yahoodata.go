@@ -1,17 +1,26 @@
 package yahoodata
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
+	"net/url"
+	"stocks/breaker"
+	"stocks/marketdata"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var YBASEURL = "https://yfapi.net/v11/finance/quoteSummary/<Ticker>?modules=price,summaryDetail,earningsTrend,earnings,earningsHistory,defaultKeyStatistics,esgScores,quoteType,majorHoldersBreakdown,majorDirectHolders,fundOwnership,balanceSheetHistoryQuarterly,recommendationTrend,institutionOwnership,upgradeDowngradeHistory,sectorTrend,indexTrend,balanceSheetHistory,cashflowStatementHistory,cashflowStatementHistoryQuarterly,incomeStatementHistoryQuarterly,incomeStatementHistory,calendarEvents,financialData,assetProfile"
 
 type YahooData struct {
 	QuoteSummary yahooDataResult `json:"quoteSummary"`
+	Raw          []byte          `json:"-"`
+	Attempts     int             `json:"-"`
 }
 type yahooDataResult struct {
 	Result []yahooDataResultObj `json:"result"`
@@ -100,631 +109,631 @@ type yahooDataCashFlowStmH struct {
 			Raw     int64  `json:"raw"`
 		} `json:"effectOfExchangeRate"`
 		EndDate struct {
-			Fmt string `json:'fmt'`
-			Raw int64  `json:'raw'`
-		} `json:'endDate'`
+			Fmt string `json:"fmt"`
+			Raw int64  `json:"raw"`
+		} `json:"endDate"`
 		Investments struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'investments'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"investments"`
 		NetBorrowings struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'netBorrowings'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"netBorrowings"`
 		NetIncome struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'netIncome'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"netIncome"`
 		OtherCashflowsFromFinancingActivities struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherCashflowsFromFinancingActivities'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherCashflowsFromFinancingActivities"`
 		OtherCashflowsFromInvestingActivities struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherCashflowsFromInvestingActivities'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherCashflowsFromInvestingActivities"`
 		RepurchaseOfStock struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'repurchaseOfStock'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"repurchaseOfStock"`
 		TotalCashflowsFromInvestingActivities struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalCashflowsFromInvestingActivities'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalCashflowsFromInvestingActivities"`
 		TotalCashFromFinancingActivities struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalCashFromFinancingActivities'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalCashFromFinancingActivities"`
 		TotalCashFromOperatingActivities struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalCashFromOperatingActivities'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalCashFromOperatingActivities"`
 	} `json:"cashflowStatements"`
 }
 type yahooDataDefaultKeyStatisticsObj struct {
 	PriceHint struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'priceHint'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"priceHint"`
 	EnterpriseValue struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'enterpriseValue'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"enterpriseValue"`
 	ForwardPE struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'forwardPE'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"forwardPE"`
 	ProfitMargins struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'profitMargins'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"profitMargins"`
 	FloatShares struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'floatShares'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"floatShares"`
 	SharesOutstanding struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'sharesOutstanding'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"sharesOutstanding"`
 	SharesShort struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'sharesShort'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"sharesShort"`
 	SharesShortPriorMonth struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'sharesShortPriorMonth'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"sharesShortPriorMonth"`
 	SharesShortPreviousMonthDate struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'sharesShortPreviousMonthDate'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"sharesShortPreviousMonthDate"`
 	DateShortInterest struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'dateShortInterest'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"dateShortInterest"`
 	SharesPercentSharesOut struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'sharesPercentSharesOut'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"sharesPercentSharesOut"`
 	HeldPercentInsiders struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'heldPercentInsiders'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"heldPercentInsiders"`
 	HeldPercentInstitutions struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'heldPercentInstitutions'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"heldPercentInstitutions"`
 	ShortRatio struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'shortRatio'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"shortRatio"`
 	ShortPercentOfFloat struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'shortPercentOfFloat'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"shortPercentOfFloat"`
 	Beta struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'beta'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"beta"`
 	ImpliedSharesOutstanding struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'impliedSharesOutstanding'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"impliedSharesOutstanding"`
 	BookValue struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'bookValue'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"bookValue"`
 	PriceToBook struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'priceToBook'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"priceToBook"`
 	LastFiscalYearEnd struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'lastFiscalYearEnd'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"lastFiscalYearEnd"`
 	NextFiscalYearEnd struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'nextFiscalYearEnd'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"nextFiscalYearEnd"`
 	MostRecentQuarter struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'mostRecentQuarter'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"mostRecentQuarter"`
 	EarningsQuarterlyGrowth struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'earningsQuarterlyGrowth'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"earningsQuarterlyGrowth"`
 	NetIncomeToCommon struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'netIncomeToCommon'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"netIncomeToCommon"`
 	TrailingEps struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'trailingEps'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"trailingEps"`
 	ForwardEps struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'forwardEps'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"forwardEps"`
 	PegRatio struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'pegRatio'`
-	LastSplitFactor string `json:'lastSplitFactor'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"pegRatio"`
+	LastSplitFactor string `json:"lastSplitFactor"`
 	LastSplitDate   struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'lastSplitDate'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"lastSplitDate"`
 	EnterpriseToRevenue struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'enterpriseToRevenue'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"enterpriseToRevenue"`
 	EnterpriseToEbitda struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'enterpriseToEbitda'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"enterpriseToEbitda"`
 	WeekChange52 struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
 	} `json:"52WeekChange"`
 	SandP52WeekChange struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'SandP52WeekChange'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"SandP52WeekChange"`
 	LastDividendValue struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'lastDividendValue'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"lastDividendValue"`
 	LastDividendDate struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'lastDividendDate'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"lastDividendDate"`
 }
 type yahooDataIncomeStmH struct {
 	IncomeStatementHistory []struct {
 		TotalRevenue struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalRevenue'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalRevenue"`
 		CostOfRevenue struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'costOfRevenue'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"costOfRevenue"`
 		GrossProfit struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'grossProfit'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"grossProfit"`
 		ResearchDevelopment struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'researchDevelopment'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"researchDevelopment"`
 		SellingGeneralAdministrative struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'sellingGeneralAdministrative'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"sellingGeneralAdministrative"`
 		NonRecurring struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'nonRecurring'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"nonRecurring"`
 		OtherOperatingExpenses struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherOperatingExpenses'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherOperatingExpenses"`
 		TotalOperatingExpenses struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalOperatingExpenses'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalOperatingExpenses"`
 		EndDate struct {
-			Fmt string `json:'fmt'`
-			Raw int64  `json:'raw'`
-		} `json:'endDate'`
+			Fmt string `json:"fmt"`
+			Raw int64  `json:"raw"`
+		} `json:"endDate"`
 		OperatingIncome struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'operatingIncome'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"operatingIncome"`
 		TotalOtherIncomeExpenseNet struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalOtherIncomeExpenseNet'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalOtherIncomeExpenseNet"`
 		Ebit struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'ebit'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"ebit"`
 		InterestExpense struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'interestExpense'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"interestExpense"`
 		IncomeBeforeTax struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'incomeBeforeTax'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"incomeBeforeTax"`
 		IncomeTaxExpense struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'incomeTaxExpense'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"incomeTaxExpense"`
 		MinorityInterest struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'minorityInterest'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"minorityInterest"`
 		NetIncomeFromContinuingOps struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'netIncomeFromContinuingOps'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"netIncomeFromContinuingOps"`
 		DiscontinuedOperations struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'discontinuedOperations'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"discontinuedOperations"`
 		ExtraordinaryItems struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'extraordinaryItems'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"extraordinaryItems"`
 		EffectOfAccountingCharges struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'effectOfAccountingCharges'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"effectOfAccountingCharges"`
 		OtherItems struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherItems'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherItems"`
 		NetIncome struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'netIncome'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"netIncome"`
 		NetIncomeApplicableToCommonShares struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'netIncomeApplicableToCommonShares'`
-	} `json:'incomeStatementHistory'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"netIncomeApplicableToCommonShares"`
+	} `json:"incomeStatementHistory"`
 }
 type yahooDataSummaryDatailObj struct {
-	Currency       string `json:'currency'`
+	Currency       string `json:"currency"`
 	ExDividendDate struct {
-		Fmt string `json:'fmt'`
-		Raw int64  `json:'raw'`
-	} `json:'exDividendDate'`
+		Fmt string `json:"fmt"`
+		Raw int64  `json:"raw"`
+	} `json:"exDividendDate"`
 	DividendRate struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'dividendRate'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"dividendRate"`
 	DividendYield struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'dividendYield'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"dividendYield"`
 	PayoutRatio struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'payoutRatio'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"payoutRatio"`
 	Beta struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'beta'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"beta"`
 	TrailingPE struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'trailingPE'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"trailingPE"`
 	ForwardPE struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'forwardPE'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"forwardPE"`
 	MarketCap struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'marketCap'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"marketCap"`
 	FiftyTwoWeekLow struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'fiftyTwoWeekLow'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"fiftyTwoWeekLow"`
 	FiftyTwoWeekHigh struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'fiftyTwoWeekHigh'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"fiftyTwoWeekHigh"`
 }
 type yahooDataCalendarEventsObj struct {
 	Earnings struct {
 		EarningsDate []struct {
-			Fmt string `json:'fmt'`
-			Raw int64  `json:'raw'`
-		} `json:'earningsDate'`
+			Fmt string `json:"fmt"`
+			Raw int64  `json:"raw"`
+		} `json:"earningsDate"`
 		EarningsAverage struct {
-			Fmt string  `json:'fmt'`
-			Raw float64 `json:'raw'`
-		} `json:'earningsAverage'`
+			Fmt string  `json:"fmt"`
+			Raw float64 `json:"raw"`
+		} `json:"earningsAverage"`
 		EarningsLow struct {
-			Fmt string  `json:'fmt'`
-			Raw float64 `json:'raw'`
-		} `json:'earningsLow'`
+			Fmt string  `json:"fmt"`
+			Raw float64 `json:"raw"`
+		} `json:"earningsLow"`
 		EarningsHigh struct {
-			Fmt string  `json:'fmt'`
-			Raw float64 `json:'raw'`
-		} `json:'earningsHigh'`
+			Fmt string  `json:"fmt"`
+			Raw float64 `json:"raw"`
+		} `json:"earningsHigh"`
 		RevenueAverage struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'revenueAverage'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"revenueAverage"`
 		RevenueLow struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'revenueLow'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"revenueLow"`
 		RevenueHigh struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'revenueHigh'`
-	} `json:'earnings'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"revenueHigh"`
+	} `json:"earnings"`
 }
 type yahooDataBalanceSheetStmH struct {
 	BalanceSheetStatements []struct {
 		Cash struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'cash'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"cash"`
 		ShortTermInvestments struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'shortTermInvestments'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"shortTermInvestments"`
 		NetReceivables struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'netReceivables'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"netReceivables"`
 		Inventory struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'inventory'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"inventory"`
 		OtherCurrentAssets struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherCurrentAssets'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherCurrentAssets"`
 		TotalCurrentAssets struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalCurrentAssets'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalCurrentAssets"`
 		LongTermInvestments struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'longTermInvestments'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"longTermInvestments"`
 		PropertyPlantEquipment struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'propertyPlantEquipment'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"propertyPlantEquipment"`
 		EndDate struct {
-			Fmt string `json:'fmt'`
-			Raw int64  `json:'raw'`
-		} `json:'endDate'`
+			Fmt string `json:"fmt"`
+			Raw int64  `json:"raw"`
+		} `json:"endDate"`
 		OtherAssets struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherAssets'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherAssets"`
 		TotalAssets struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalAssets'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalAssets"`
 		AccountsPayable struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'accountsPayable'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"accountsPayable"`
 		ShortLongTermDebt struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'shortLongTermDebt'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"shortLongTermDebt"`
 		OtherCurrentLiab struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherCurrentLiab'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherCurrentLiab"`
 		LongTermDebt struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'longTermDebt'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"longTermDebt"`
 		OtherLiab struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherLiab'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherLiab"`
 		TotalCurrentLiabilities struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalCurrentLiabilities'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalCurrentLiabilities"`
 		TotalLiab struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalLiab'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalLiab"`
 		CommonStock struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'commonStock'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"commonStock"`
 		RetainedEarnings struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'retainedEarnings'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"retainedEarnings"`
 		TreasuryStock struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'treasuryStock'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"treasuryStock"`
 		OtherStockholderEquity struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'otherStockholderEquity'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"otherStockholderEquity"`
 		TotalStockholderEquity struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'totalStockholderEquity'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"totalStockholderEquity"`
 		NetTangibleAssets struct {
-			Fmt     string `json:'fmt'`
-			LongFmt string `json:'longFmt'`
-			Raw     int64  `json:'raw'`
-		} `json:'netTangibleAssets'`
-	} `json:'balanceSheetStatements'`
+			Fmt     string `json:"fmt"`
+			LongFmt string `json:"longFmt"`
+			Raw     int64  `json:"raw"`
+		} `json:"netTangibleAssets"`
+	} `json:"balanceSheetStatements"`
 }
 type yahooDataEarningsTrendObj struct {
 	Trend []struct {
-		Period  string `json:'period'`
-		EndDate string `json:'endDate'`
+		Period  string `json:"period"`
+		EndDate string `json:"endDate"`
 		Growth  struct {
-			Fmt string  `json:'fmt'`
-			Raw float64 `json:'raw'`
-		} `json:'growth'`
-	} `json:'trend'`
+			Fmt string  `json:"fmt"`
+			Raw float64 `json:"raw"`
+		} `json:"growth"`
+	} `json:"trend"`
 }
 type yahooDataFinancialDataObj struct {
 	CurrentPrice struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'currentPrice'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"currentPrice"`
 	TargetHighPrice struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'targetHighPrice'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"targetHighPrice"`
 	TargetLowPrice struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'targetLowPrice'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"targetLowPrice"`
 	TargetMedianPrice struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'targetMedianPrice'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"targetMedianPrice"`
 	RecommendationMean struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'recommendationMean'`
-	RecommendationKey       string `json:'recommendationKey'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"recommendationMean"`
+	RecommendationKey       string `json:"recommendationKey"`
 	NumberOfAnalystOpinions struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'numberOfAnalystOpinions'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"numberOfAnalystOpinions"`
 	TotalCash struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'totalCash'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"totalCash"`
 	TotalCashPerShare struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'totalCashPerShare'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"totalCashPerShare"`
 	Ebitda struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'ebitda'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"ebitda"`
 	TotalDebt struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'totalDebt'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"totalDebt"`
 	QuickRatio struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'quickRatio'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"quickRatio"`
 	CurrentRatio struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'currentRatio'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"currentRatio"`
 	TotalRevenue struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'totalRevenue'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"totalRevenue"`
 	DebtToEquity struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'debtToEquity'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"debtToEquity"`
 	RevenuePerShare struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'revenuePerShare'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"revenuePerShare"`
 	ReturnOnAssets struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'returnOnAssets'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"returnOnAssets"`
 	ReturnOnEquity struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'returnOnEquity'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"returnOnEquity"`
 	GrossProfits struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'grossProfits'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"grossProfits"`
 	FreeCashflow struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'freeCashflow'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"freeCashflow"`
 	OperatingCashflow struct {
-		Fmt     string `json:'fmt'`
-		LongFmt string `json:'longFmt'`
-		Raw     int64  `json:'raw'`
-	} `json:'operatingCashflow'`
+		Fmt     string `json:"fmt"`
+		LongFmt string `json:"longFmt"`
+		Raw     int64  `json:"raw"`
+	} `json:"operatingCashflow"`
 	GrossMargins struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'grossMargins'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"grossMargins"`
 	EbitdaMargins struct {
-		Fmt string  `json:'fmt'`
-		Raw float64 `json:'raw'`
-	} `json:'ebitdaMargins'`
+		Fmt string  `json:"fmt"`
+		Raw float64 `json:"raw"`
+	} `json:"ebitdaMargins"`
 	OperatingMargins struct {
 		Fmt string  `json:"fmt"`
 		Raw float64 `json:"raw"`
@@ -741,34 +750,151 @@ type yahooDataPriceObj struct {
 	LongName     string `json:"longName"`
 }
 
-func NewData(apikey string, ticker string) *YahooData {
-	p := new(YahooData)
+// StatusError is returned by NewData when yfapi.net answers with a status
+// other than 200. RetryAfter is the delay asked for in a Retry-After header
+// and Message the message field of the JSON error body.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Message    string
+}
+
+// quotaMessage is the error message yfapi.net sends with a 429 when the
+// quota of the API key is used up. Rate limiting answers say "Too Many
+// Requests" instead.
+const quotaMessage = "limit exceeded"
+
+// QuotaExceeded tells whether err is the 429 yfapi.net sends when the quota
+// of the API key is used up. Other 429s are rate limiting and are retried.
+func QuotaExceeded(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests &&
+		strings.EqualFold(strings.TrimSpace(statusErr.Message), quotaMessage)
+}
+
+func (e *StatusError) Error() string {
+	return "yahoo returned status " + strconv.Itoa(e.StatusCode)
+}
+
+// Breaker stops calls to yfapi.net after repeated failures. NewData then
+// returns an error wrapping breaker.ErrOpen without making a request.
+var Breaker = breaker.New("yahoo", 5, 30*time.Second)
+
+// HTTPClient sends the requests to yfapi.net. Timeouts come from the
+// context passed to NewData.
+var HTTPClient = http.DefaultClient
+
+// Observe, when set, is called after NewData talked to yfapi.net with the
+// time taken including retries and the final error.
+var Observe func(d time.Duration, err error)
+
+// NewData fetches the quoteSummary of ticker, retrying transient failures
+// as set by DefaultRetryPolicy. Errors are returned as a
+// *marketdata.FetchError holding the number of attempts. The request is
+// cancelled when ctx is done.
+func NewData(ctx context.Context, apikey string, ticker string) (*YahooData, error) {
+	if err := Breaker.Allow(); err != nil {
+		return nil, &marketdata.FetchError{Err: err}
+	}
+
+	start := time.Now()
+	body, attempts, err := getDataWithRetry(ctx, apikey, ticker)
+	if Observe != nil {
+		Observe(time.Since(start), err)
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		Breaker.Ignore()
+	case err != nil && unhealthy(err):
+		Breaker.Failure()
+	default:
+		Breaker.Success()
+	}
+	if err != nil {
+		return nil, &marketdata.FetchError{Attempts: attempts, Err: err}
+	}
+
+	d, err := ParseData(body)
+	if err != nil {
+		return nil, &marketdata.FetchError{Attempts: attempts, Err: err}
+	}
+	d.Attempts = attempts
+	return d, nil
+}
+
+func getDataWithRetry(ctx context.Context, apikey string, ticker string) ([]byte, int, error) {
+	policy := DefaultRetryPolicy
+	for attempt := 1; ; attempt++ {
+		body, err := getData(ctx, apikey, ticker)
+		if err == nil {
+			return body, attempt, nil
+		}
+
+		if attempt >= policy.MaxAttempts || !retryable(err) {
+			return nil, attempt, err
+		}
+		delay := policy.backoff(attempt)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+			delay = statusErr.RetryAfter
+		}
+		if !wait(ctx, delay) {
+			return nil, attempt, err
+		}
+	}
+}
 
-	yLink := strings.Replace(YBASEURL, "<Ticker>", ticker, -1)
+// getData makes one request.
+func getData(ctx context.Context, apikey string, ticker string) ([]byte, error) {
+	yLink := strings.Replace(YBASEURL, "<Ticker>", url.PathEscape(ticker), -1)
 
-	req, err := http.NewRequest("GET", yLink, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", yLink, nil)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	req.Header.Set("x-api-key", apikey)
-	client := &http.Client{}
-	yresp, err := client.Do(req)
+	yresp, err := HTTPClient.Do(req)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	defer yresp.Body.Close()
 
 	if yresp.StatusCode != http.StatusOK {
-		return nil
+		return nil, &StatusError{
+			StatusCode: yresp.StatusCode,
+			RetryAfter: retryAfter(yresp.Header.Get("Retry-After")),
+			Message:    errorMessage(yresp.Body),
+		}
 	}
 
 	ybody, err := ioutil.ReadAll(yresp.Body)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	err = json.Unmarshal(ybody, &p)
-	if err != nil {
-		log.Fatal(err)
+
+	return ybody, nil
+}
+
+// errorMessage returns the message field of an error body, or "" when the
+// body is not a JSON object with one.
+func errorMessage(body io.Reader) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, 4096)).Decode(&e); err != nil {
+		return ""
+	}
+	return e.Message
+}
+
+// ParseData decodes a quoteSummary response body. The body is kept in Raw so
+// it can be archived and reprocessed later.
+func ParseData(body []byte) (*YahooData, error) {
+	p := new(YahooData)
+	if err := json.Unmarshal(body, p); err != nil {
+		return nil, err
 	}
+	p.Raw = body
 
-	return p
+	return p, nil
 }